@@ -14,11 +14,19 @@ type Config struct {
 		HttpPort string `yaml:"http_port" env-default:"8080"`
 		GrpcPort string `yaml:"grpc_port" env-default:"8080"`
 	} `yaml:"listen"`
-	Storage         StorageConfig `yaml:"storage"`
-	LevelDebug      string        `yaml:"level_debug"`
-	TTLAccessToken  int           `yaml:"ttl_access_token"`
-	TTLRefreshToken int           `yaml:"ttl_refresh_token"`
-	SecretKey       string        `yaml:"secret_key"`
+	Storage           StorageConfig    `yaml:"storage"`
+	LevelDebug        string           `yaml:"level_debug"`
+	TTLAccessToken    int              `yaml:"ttl_access_token"`
+	TTLRefreshToken   int              `yaml:"ttl_refresh_token"`
+	SecretKey         string           `yaml:"secret_key"`
+	ReservationTTL    int              `yaml:"reservation_ttl"`
+	SweepInterval     int              `yaml:"sweep_interval" env-default:"1"`
+	IdempotencyWindow int              `yaml:"idempotency_window" env-default:"1440"`
+	Notifier          NotifierConfig   `yaml:"notifier"`
+	ReconcileInterval int              `yaml:"reconcile_interval"`
+	ReconcileFix      bool             `yaml:"reconcile_fix"`
+	SplitStrategy     string           `yaml:"split_strategy" env-default:"preferred"`
+	Adjustment        AdjustmentConfig `yaml:"adjustment"`
 }
 
 type StorageConfig struct {
@@ -30,6 +38,20 @@ type StorageConfig struct {
 	SSLMode  string `yaml:"ssl_mode"`
 }
 
+type NotifierConfig struct {
+	Type       string `yaml:"type" env-default:"log"`
+	WebhookURL string `yaml:"webhook_url"`
+	Timeout    int    `yaml:"timeout" env-default:"5"`
+}
+
+// AdjustmentConfig lists the reason codes accepted for stock adjustments. Adjustments changing
+// the stock by more than ApprovalLimit units wait for an admin to approve them; zero applies
+// every adjustment right away.
+type AdjustmentConfig struct {
+	Reasons       []string `yaml:"reasons" env-default:"damaged,lost,found,cycle-count"`
+	ApprovalLimit int      `yaml:"approval_limit"`
+}
+
 var instance *Config
 var once sync.Once
 
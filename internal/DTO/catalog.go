@@ -0,0 +1,35 @@
+package DTO
+
+import "time"
+
+type ReqProduct struct {
+	UniqueCode string `json:"unique_code"`
+	Name       string `json:"name"`
+	Size       int    `json:"size"`
+	Serialized bool   `json:"serialized"`
+}
+
+type ReqGetProduct struct {
+	UniqueCode string `json:"unique_code"`
+}
+
+type Product struct {
+	ID         int        `json:"id"`
+	UniqueCode string     `json:"unique_code"`
+	Name       string     `json:"name"`
+	Size       int        `json:"size"`
+	Serialized bool       `json:"serialized"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ReqListProducts struct {
+	Name        string `json:"name"`
+	WithDeleted bool   `json:"with_deleted"`
+	Cursor      int    `json:"cursor"`
+	Limit       int    `json:"limit"`
+}
+
+type ResListProducts struct {
+	Products   []Product `json:"products"`
+	NextCursor int       `json:"next_cursor,omitempty"`
+}
@@ -1,20 +1,54 @@
 package DTO
 
+import "time"
+
 type ReqReserveProduct struct {
-	WarehouseID int      `json:"warehouse_id"`
-	UniqueCodes []string `json:"unique_codes"`
-	Counts      []int    `json:"counts"`
+	WarehouseID          int      `json:"warehouse_id"`
+	UniqueCodes          []string `json:"unique_codes"`
+	Counts               []int    `json:"counts"`
+	Atomic               bool     `json:"atomic"`
+	TTL                  int      `json:"ttl"`
+	Backorder            bool     `json:"backorder"`
+	Split                bool     `json:"split"`
+	PreferredWarehouseID int      `json:"preferred_warehouse_id"`
+	Strategy             string   `json:"strategy"`
 }
 
 type ResReserveProduct struct {
 	Successful   []Successful `json:"successful"`
+	Backordered  []Backorder  `json:"backordered,omitempty"`
 	Unsuccessful []string     `json:"unsuccessful"`
 	Errors       []string     `json:"errors"`
 }
 
 type Successful struct {
-	ID         int    `json:"id"`
-	UniqueCode string `json:"unique_codes"`
+	ID            int        `json:"id"`
+	UniqueCode    string     `json:"unique_codes"`
+	WarehouseID   int        `json:"warehouse_id,omitempty"`
+	Count         int        `json:"count,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Lots          []LotCount `json:"lots,omitempty"`
+	SerialNumbers []string   `json:"serial_numbers,omitempty"`
+}
+
+type ResQuoteReservation struct {
+	Reservable bool        `json:"reservable"`
+	Lines      []QuoteLine `json:"lines"`
+}
+
+type QuoteLine struct {
+	UniqueCode   string       `json:"unique_code"`
+	Count        int          `json:"count"`
+	WouldSucceed bool         `json:"would_succeed"`
+	Backordered  bool         `json:"backordered,omitempty"`
+	Remaining    int          `json:"remaining"`
+	Chunks       []QuoteChunk `json:"chunks,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+type QuoteChunk struct {
+	WarehouseID int `json:"warehouse_id"`
+	Count       int `json:"count"`
 }
 
 type ReqFreeReservation struct {
@@ -23,6 +57,86 @@ type ReqFreeReservation struct {
 
 type ResFreeReservation struct {
 	Successful   []int    `json:"successful"`
+	Restored     []int    `json:"restored"`
 	Unsuccessful []int    `json:"unsuccessful"`
 	Errors       []string `json:"errors"`
 }
+
+type ReqExtendReservation struct {
+	ID  int `json:"id"`
+	TTL int `json:"ttl"`
+}
+
+type ResExtendReservation struct {
+	ID        int       `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ReqChangeReservationStatus struct {
+	ID int `json:"id"`
+}
+
+type ResChangeReservationStatus struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+type ReqGetReservation struct {
+	ID int `json:"id"`
+}
+
+type Reservation struct {
+	ID            int        `json:"id"`
+	WarehouseID   int        `json:"warehouse_id"`
+	UniqueCode    string     `json:"unique_code"`
+	Count         int        `json:"count"`
+	Status        string     `json:"status"`
+	Owner         string     `json:"owner"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Lots          []LotCount `json:"lots,omitempty"`
+	SerialNumbers []string   `json:"serial_numbers,omitempty"`
+}
+
+type ReqListReservations struct {
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	Owner       string `json:"owner"`
+	Status      string `json:"status"`
+	Cursor      int    `json:"cursor"`
+	Limit       int    `json:"limit"`
+}
+
+type ResListReservations struct {
+	Reservations []Reservation `json:"reservations"`
+	NextCursor   int           `json:"next_cursor,omitempty"`
+}
+
+type Backorder struct {
+	ID            int       `json:"id"`
+	WarehouseID   int       `json:"warehouse_id"`
+	UniqueCode    string    `json:"unique_code"`
+	Count         int       `json:"count"`
+	Status        string    `json:"status"`
+	Owner         string    `json:"owner"`
+	ReservationID int       `json:"reservation_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ReqCancelBackorder struct {
+	ID int `json:"id"`
+}
+
+type ReqListBackorders struct {
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	Owner       string `json:"owner"`
+	Status      string `json:"status"`
+	Cursor      int    `json:"cursor"`
+	Limit       int    `json:"limit"`
+}
+
+type ResListBackorders struct {
+	Backorders []Backorder `json:"backorders"`
+	NextCursor int         `json:"next_cursor,omitempty"`
+}
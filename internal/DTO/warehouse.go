@@ -1,9 +1,357 @@
 package DTO
 
+import "time"
+
 type ReqGetProducts struct {
-	WarehouseID int `json:"warehouse_id"`
+	WarehouseID int    `json:"warehouse_id"`
+	CodePrefix  string `json:"code_prefix"`
+	Name        string `json:"name"`
+	InStock     bool   `json:"in_stock"`
+	Sort        string `json:"sort"`
+	Desc        bool   `json:"desc"`
+	Cursor      string `json:"cursor"`
+	Limit       int    `json:"limit"`
+}
+
+type ProductStock struct {
+	UniqueCode string `json:"unique_code"`
+	Name       string `json:"name"`
+	Size       int    `json:"size"`
+	TotalCount int    `json:"total_count"`
+	LeftCount  int    `json:"left_count"`
+	Reserved   int    `json:"reserved"`
 }
 
 type ResGetProducts struct {
-	ProductsCodes []string `json:"products_codes"`
+	Products   []ProductStock `json:"products"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type ReqGetMovements struct {
+	WarehouseID int       `json:"warehouse_id"`
+	UniqueCode  string    `json:"unique_code"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Cursor      int       `json:"cursor"`
+	Limit       int       `json:"limit"`
+}
+
+type StockMovement struct {
+	ID            int       `json:"id"`
+	Delta         int       `json:"delta"`
+	TotalDelta    int       `json:"total_delta"`
+	Reason        string    `json:"reason"`
+	ReservationID int       `json:"reservation_id,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ResGetMovements struct {
+	Movements  []StockMovement `json:"movements"`
+	NextCursor int             `json:"next_cursor,omitempty"`
+}
+
+type ReqReceiveProduct struct {
+	WarehouseID   int        `json:"warehouse_id"`
+	UniqueCodes   []string   `json:"unique_codes"`
+	Counts        []int      `json:"counts"`
+	LotNumbers    []string   `json:"lot_numbers"`
+	ExpiryDates   []string   `json:"expiry_dates"`
+	SerialNumbers [][]string `json:"serial_numbers"`
+}
+
+type ResReceiveProduct struct {
+	Successful   []Received `json:"successful"`
+	Unsuccessful []string   `json:"unsuccessful"`
+	Errors       []string   `json:"errors"`
+}
+
+type Received struct {
+	UniqueCode string `json:"unique_code"`
+	TotalCount int    `json:"total_count"`
+	LeftCount  int    `json:"left_count"`
+	Lot        *Lot   `json:"lot,omitempty"`
+}
+
+type Lot struct {
+	ID          int    `json:"id"`
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	LotNumber   string `json:"lot_number"`
+	ExpiryDate  string `json:"expiry_date,omitempty"`
+	TotalCount  int    `json:"total_count"`
+	LeftCount   int    `json:"left_count"`
+}
+
+type LotCount struct {
+	LotID      int    `json:"lot_id,omitempty"`
+	LotNumber  string `json:"lot_number"`
+	ExpiryDate string `json:"expiry_date,omitempty"`
+	Count      int    `json:"count"`
+}
+
+type ReqGetExpiringLots struct {
+	WarehouseID int `json:"warehouse_id"`
+	Days        int `json:"days"`
+}
+
+type ResGetExpiringLots struct {
+	Lots []Lot `json:"lots"`
+}
+
+type ReqGetSerialNumber struct {
+	SerialNumber string `json:"serial_number"`
+	UniqueCode   string `json:"unique_code"`
+}
+
+type SerialNumber struct {
+	SerialNumber  string        `json:"serial_number"`
+	UniqueCode    string        `json:"unique_code"`
+	WarehouseID   int           `json:"warehouse_id"`
+	Status        string        `json:"status"`
+	ReservationID int           `json:"reservation_id,omitempty"`
+	TransferID    int           `json:"transfer_id,omitempty"`
+	History       []SerialEvent `json:"history"`
+}
+
+type SerialEvent struct {
+	Status        string    `json:"status"`
+	WarehouseID   int       `json:"warehouse_id"`
+	ReservationID int       `json:"reservation_id,omitempty"`
+	TransferID    int       `json:"transfer_id,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ResGetSerialNumber struct {
+	SerialNumbers []SerialNumber `json:"serial_numbers"`
+}
+
+type ReqTransferProduct struct {
+	FromWarehouseID int    `json:"from_warehouse_id"`
+	ToWarehouseID   int    `json:"to_warehouse_id"`
+	UniqueCode      string `json:"unique_code"`
+	Count           int    `json:"count"`
+	InTransit       bool   `json:"in_transit"`
+}
+
+type ReqReceiveTransfer struct {
+	ID int `json:"id"`
+}
+
+type Transfer struct {
+	ID              int        `json:"id"`
+	FromWarehouseID int        `json:"from_warehouse_id"`
+	ToWarehouseID   int        `json:"to_warehouse_id"`
+	UniqueCode      string     `json:"unique_code"`
+	Count           int        `json:"count"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ReceivedAt      *time.Time `json:"received_at,omitempty"`
+	Lots            []LotCount `json:"lots,omitempty"`
+	SerialNumbers   []string   `json:"serial_numbers,omitempty"`
+}
+
+type ReqSetReorderThreshold struct {
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	Threshold   int    `json:"threshold"`
+}
+
+type StockLevel struct {
+	WarehouseID      int    `json:"warehouse_id"`
+	UniqueCode       string `json:"unique_code"`
+	TotalCount       int    `json:"total_count"`
+	LeftCount        int    `json:"left_count"`
+	ReorderThreshold int    `json:"reorder_threshold"`
+}
+
+type ResGetLowStock struct {
+	Items []StockLevel `json:"items"`
+}
+
+type ReqReconcile struct {
+	Fix bool `json:"fix"`
+}
+
+type Discrepancy struct {
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	TotalCount  int    `json:"total_count"`
+	LeftCount   int    `json:"left_count"`
+	Reserved    int    `json:"reserved"`
+	Expected    int    `json:"expected"`
+	Fixed       bool   `json:"fixed"`
+}
+
+type ResReconcile struct {
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+type Warehouse struct {
+	ID        int                  `json:"id"`
+	Name      string               `json:"name"`
+	Available bool                 `json:"available"`
+	Capacity  int                  `json:"capacity"`
+	History   []AvailabilityChange `json:"history,omitempty"`
+}
+
+type AvailabilityChange struct {
+	Available bool      `json:"available"`
+	Reason    string    `json:"reason"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReqCreateWarehouse struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Capacity  int    `json:"capacity"`
+}
+
+type ReqUpdateWarehouse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Capacity *int   `json:"capacity"`
+}
+
+type ReqGetWarehouse struct {
+	ID int `json:"id"`
+}
+
+type ResListWarehouses struct {
+	Warehouses []Warehouse `json:"warehouses"`
+}
+
+type ReqSetWarehouseAvailability struct {
+	ID        int    `json:"id"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason"`
+}
+
+type Utilization struct {
+	WarehouseID int      `json:"warehouse_id"`
+	Name        string   `json:"name"`
+	Capacity    int      `json:"capacity"`
+	Occupied    int      `json:"occupied"`
+	InTransit   int      `json:"in_transit"`
+	Free        *int     `json:"free,omitempty"`
+	Percent     *float64 `json:"percent,omitempty"`
+}
+
+type ResGetUtilization struct {
+	Warehouses []Utilization `json:"warehouses"`
+}
+
+type ReqGetAvailability struct {
+	UniqueCodes []string `json:"unique_codes"`
+}
+
+type WarehouseStock struct {
+	WarehouseID int `json:"warehouse_id"`
+	LeftCount   int `json:"left_count"`
+}
+
+type ProductAvailability struct {
+	UniqueCode string           `json:"unique_code"`
+	Total      int              `json:"total"`
+	Warehouses []WarehouseStock `json:"warehouses"`
+}
+
+type ResGetAvailability struct {
+	Products []ProductAvailability `json:"products"`
+}
+
+type ReqAdjustStock struct {
+	WarehouseID   int      `json:"warehouse_id"`
+	UniqueCode    string   `json:"unique_code"`
+	Delta         int      `json:"delta"`
+	Reason        string   `json:"reason"`
+	Note          string   `json:"note"`
+	SerialNumbers []string `json:"serial_numbers"`
+}
+
+type Adjustment struct {
+	ID            int        `json:"id"`
+	WarehouseID   int        `json:"warehouse_id"`
+	UniqueCode    string     `json:"unique_code"`
+	Delta         int        `json:"delta"`
+	Reason        string     `json:"reason"`
+	Note          string     `json:"note,omitempty"`
+	SerialNumbers []string   `json:"serial_numbers,omitempty"`
+	Status        string     `json:"status"`
+	CreatedBy     string     `json:"created_by"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	Lots          []LotCount `json:"lots,omitempty"`
+}
+
+type ReqReviewAdjustment struct {
+	ID int `json:"id"`
+}
+
+type ReqListAdjustments struct {
+	WarehouseID int    `json:"warehouse_id"`
+	UniqueCode  string `json:"unique_code"`
+	Status      string `json:"status"`
+	Cursor      int    `json:"cursor"`
+	Limit       int    `json:"limit"`
+}
+
+type ResListAdjustments struct {
+	Adjustments []Adjustment `json:"adjustments"`
+	NextCursor  int          `json:"next_cursor,omitempty"`
+}
+
+type ReqOpenCycleCount struct {
+	WarehouseID int      `json:"warehouse_id"`
+	UniqueCodes []string `json:"unique_codes"`
+}
+
+type CycleCount struct {
+	ID          int              `json:"id"`
+	WarehouseID int              `json:"warehouse_id"`
+	UniqueCodes []string         `json:"unique_codes,omitempty"`
+	Status      string           `json:"status"`
+	CreatedBy   string           `json:"created_by"`
+	ClosedBy    string           `json:"closed_by,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ClosedAt    *time.Time       `json:"closed_at,omitempty"`
+	Lines       []CycleCountLine `json:"lines"`
+}
+
+type CycleCountLine struct {
+	UniqueCode   string    `json:"unique_code"`
+	Counted      int       `json:"counted"`
+	Expected     int       `json:"expected"`
+	Variance     int       `json:"variance"`
+	Status       string    `json:"status"`
+	Adjusted     int       `json:"adjusted,omitempty"`
+	Unresolved   int       `json:"unresolved,omitempty"`
+	AdjustmentID int       `json:"adjustment_id,omitempty"`
+	CountedBy    string    `json:"counted_by"`
+	CountedAt    time.Time `json:"counted_at"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type ReqSubmitCycleCount struct {
+	ID          int      `json:"id"`
+	UniqueCodes []string `json:"unique_codes"`
+	Counts      []int    `json:"counts"`
+}
+
+type ResSubmitCycleCount struct {
+	Successful   []CycleCountLine `json:"successful"`
+	Unsuccessful []string         `json:"unsuccessful"`
+	Errors       []string         `json:"errors"`
+}
+
+type ReqGetCycleCount struct {
+	ID int `json:"id"`
+}
+
+type ReqCloseCycleCount struct {
+	ID          int      `json:"id"`
+	UniqueCodes []string `json:"unique_codes"`
 }
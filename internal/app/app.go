@@ -2,6 +2,7 @@ package app
 
 import (
 	"example1/config"
+	"example1/internal/DTO"
 	"example1/internal/app/grpc"
 	servhttp "example1/internal/app/http_server"
 	"example1/internal/handler"
@@ -9,6 +10,7 @@ import (
 	repo "example1/internal/repository/sqlc/generate"
 	"example1/internal/service"
 	log "example1/pkg/logger"
+	"example1/pkg/notifier"
 	"example1/pkg/postgres"
 	"net"
 	"time"
@@ -45,16 +47,52 @@ func (a *App) Run() {
 	userRepository := repo.New(cl)
 	productRepository := repository.NewProductRepository(cl)
 	warehouseRepository := repository.NewWarehouseRepository(cl)
+	idempotencyRepository := repository.NewIdempotencyRepository(cl)
+	catalogRepository := repository.NewCatalogRepository(cl)
+
+	stockNotifier := notifier.New(a.Config.Notifier)
 
 	authService := service.NewAuthService(*userRepository, *a.Config)
-	productService := service.NewProductService(productRepository, warehouseRepository)
-	warehouseService := service.NewWarehouseService(warehouseRepository)
+	productService := service.NewProductService(productRepository, warehouseRepository, *a.Config, stockNotifier)
+	warehouseService := service.NewWarehouseService(warehouseRepository, *a.Config, stockNotifier)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepository, *a.Config)
+	catalogService := service.NewCatalogService(catalogRepository)
 
 	middleware := handler.NewAuthHandler(authService)
 	warehouseHandler := handler.NewWarehouseHandler(warehouseService, middleware)
-	productHandler := handler.NewProductHandler(productService, middleware)
+	idempotencyHandler := handler.NewIdempotencyHandler(idempotencyService)
+	productHandler := handler.NewProductHandler(productService, middleware, idempotencyHandler)
+	catalogHandler := handler.NewCatalogHandler(catalogService, middleware)
+
+	go a.HttpServer.ListenAndServe(productHandler, warehouseHandler, catalogHandler)
 
-	go a.HttpServer.ListenAndServe(warehouseHandler, productHandler)
+	if a.Config.SweepInterval > 0 {
+		go a.runPeriodically("release expired reservations", time.Duration(a.Config.SweepInterval)*time.Minute,
+			func() error {
+				_, err := productService.ReleaseExpired()
+				return err
+			})
+		go a.runPeriodically("delete expired idempotency keys", time.Duration(a.Config.SweepInterval)*time.Minute,
+			func() error {
+				_, err := idempotencyService.DeleteExpired()
+				return err
+			})
+	}
+
+	if a.Config.ReconcileInterval > 0 {
+		go a.runPeriodically("reconcile inventory", time.Duration(a.Config.ReconcileInterval)*time.Minute,
+			func() error {
+				res, err := warehouseService.Reconcile(&DTO.ReqReconcile{Fix: a.Config.ReconcileFix}, &service.AuthInfo{})
+				if err != nil {
+					return err
+				}
+				for _, d := range res.Discrepancies {
+					a.Logger.Warnf("inventory discrepancy in warehouse %d for %s: left %d, expected %d, fixed %t",
+						d.WarehouseID, d.UniqueCode, d.LeftCount, d.Expected, d.Fixed)
+				}
+				return nil
+			})
+	}
 
 	a.Logger.Info("starting http server")
 
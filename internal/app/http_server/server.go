@@ -22,13 +22,15 @@ func New() HttpServer {
 }
 
 type HttpServer interface {
-	ListenAndServe(handler.ProductHandler, handler.WarehouseHandler)
+	ListenAndServe(handler.ProductHandler, handler.WarehouseHandler, handler.CatalogHandler)
 }
 
-func (h *httpServer) ListenAndServe(productHandler handler.ProductHandler, warehouseHandler handler.WarehouseHandler) {
+func (h *httpServer) ListenAndServe(productHandler handler.ProductHandler, warehouseHandler handler.WarehouseHandler,
+	catalogHandler handler.CatalogHandler) {
 	h.Logger.Info("starting ListenAndServe server")
 	productHandler.Register(h.Router)
 	warehouseHandler.Register(h.Router)
+	catalogHandler.Register(h.Router)
 	err := h.Router.Run(h.Config.Listen.HttpPort)
 	if err != nil {
 		h.Logger.Fatal(err)
@@ -0,0 +1,17 @@
+package app
+
+import "time"
+
+// runPeriodically calls job every interval for the lifetime of the process, logging its errors.
+func (a *App) runPeriodically(name string, interval time.Duration, job func() error) {
+	a.Logger.Info("starting background job ", name)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := job(); err != nil {
+			a.Logger.Error(name, ": ", err)
+		}
+	}
+}
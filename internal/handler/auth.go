@@ -52,3 +52,37 @@ func (h *authHandler) Authorize(c *gin.Context) {
 
 	c.Next()
 }
+
+// allow reports whether the user set by Authorize has one of the given roles. Otherwise it
+// aborts the request with 401 or 403 and returns false.
+func allow(c *gin.Context, roles ...int32) bool {
+	temp, ok := c.Get("role")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return false
+	}
+
+	userRole, ok := temp.(int32)
+	if ok {
+		for _, role := range roles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	return false
+}
+
+// currentUser returns the user set by Authorize.
+func currentUser(c *gin.Context) *service.AuthInfo {
+	role, _ := c.Get("role")
+	userRole, _ := role.(int32)
+
+	return &service.AuthInfo{
+		ID:    c.GetString("id"),
+		Login: c.GetString("login"),
+		Role:  userRole,
+	}
+}
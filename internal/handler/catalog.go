@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/service"
+	"example1/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type catalogHandler struct {
+	Service    service.CatalogService
+	Middleware AuthHandler
+	Logger     logger.Logger
+}
+
+func NewCatalogHandler(s service.CatalogService, m AuthHandler) CatalogHandler {
+	return &catalogHandler{
+		Service:    s,
+		Middleware: m,
+		Logger:     logger.Get(),
+	}
+}
+
+type CatalogHandler interface {
+	Register(r *gin.Engine)
+}
+
+func (h *catalogHandler) Register(router *gin.Engine) {
+	router.Handle(http.MethodPost, "/CreateProduct", h.Middleware.Authorize, h.CreateProduct)
+	router.Handle(http.MethodPost, "/UpdateProduct", h.Middleware.Authorize, h.UpdateProduct)
+	router.Handle(http.MethodPost, "/GetProduct", h.Middleware.Authorize, h.GetProduct)
+	router.Handle(http.MethodPost, "/ListProducts", h.Middleware.Authorize, h.ListProducts)
+	router.Handle(http.MethodPost, "/DeleteProduct", h.Middleware.Authorize, h.DeleteProduct)
+}
+
+func (h *catalogHandler) CreateProduct(c *gin.Context) {
+	h.Logger.Info("start handler CreateProduct")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqProduct{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.CreateProduct(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, res)
+}
+
+func (h *catalogHandler) UpdateProduct(c *gin.Context) {
+	h.Logger.Info("start handler UpdateProduct")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqProduct{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.UpdateProduct(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *catalogHandler) GetProduct(c *gin.Context) {
+	h.Logger.Info("start handler GetProduct")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqGetProduct{}
+	err := c.BindJSON(&req)
+	if err != nil || req.UniqueCode == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetProduct(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *catalogHandler) ListProducts(c *gin.Context) {
+	h.Logger.Info("start handler ListProducts")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqListProducts{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.ListProducts(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *catalogHandler) DeleteProduct(c *gin.Context) {
+	h.Logger.Info("start handler DeleteProduct")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqGetProduct{}
+	err := c.BindJSON(&req)
+	if err != nil || req.UniqueCode == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.DeleteProduct(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+// abortWithError responds with the status matching a catalog service error.
+func (h *catalogHandler) abortWithError(c *gin.Context, err error) {
+	h.Logger.Error(err)
+	switch {
+	case errors.Is(err, service.ErrNonExistProduct):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrProductExists), errors.Is(err, service.ErrProductInStock),
+		errors.Is(err, service.ErrProductReserved):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInternal):
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternalError.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
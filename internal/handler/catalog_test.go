@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"example1/internal/DTO"
+	"example1/internal/service"
+	mock_service "example1/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCatalogHandler_Register(t *testing.T) {
+	type mockCatalogBehaviour func(s mock_service.MockCatalogService)
+
+	testTable := &[]struct {
+		name                 string
+		path                 string
+		role                 int32
+		requestBody          string
+		mockCatalogBehaviour mockCatalogBehaviour
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Create",
+			path:        "/CreateProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"olkiuj\", \"name\": \"chair\", \"size\": 3}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().CreateProduct(&DTO.ReqProduct{UniqueCode: "olkiuj", Name: "chair", Size: 3}).Return(
+					&DTO.Product{ID: 1, UniqueCode: "olkiuj", Name: "chair", Size: 3},
+					nil,
+				)
+			},
+			expectedStatusCode:   201,
+			expectedResponseBody: "{\"id\":1,\"unique_code\":\"olkiuj\",\"name\":\"chair\",\"size\":3,\"serialized\":false}",
+		},
+		{
+			name:        "Create - duplicate unique code",
+			path:        "/CreateProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"olkiuj\", \"name\": \"table\", \"size\": 5}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().CreateProduct(&DTO.ReqProduct{UniqueCode: "olkiuj", Name: "table", Size: 5}).Return(
+					nil,
+					service.ErrProductExists,
+				)
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: "{\"error\":\"product with this unique code already exists\"}",
+		},
+		{
+			name:        "Create - invalid product",
+			path:        "/CreateProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"tghyuj\", \"size\": 5}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().CreateProduct(&DTO.ReqProduct{UniqueCode: "tghyuj", Size: 5}).Return(
+					nil,
+					service.ErrInvalidProduct,
+				)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: "{\"error\":\"invalid product\"}",
+		},
+		{
+			name:        "Create - forbidden",
+			path:        "/CreateProduct",
+			role:        productWorker,
+			requestBody: "{\"unique_code\": \"tghyuj\", \"name\": \"table\", \"size\": 5}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: "{\"error\":\"forbidden\"}",
+		},
+		{
+			name:        "Create - serialized",
+			path:        "/CreateProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"lkjhgf\", \"name\": \"laptop\", \"size\": 2, \"serialized\": true}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().CreateProduct(&DTO.ReqProduct{UniqueCode: "lkjhgf", Name: "laptop", Size: 2, Serialized: true}).Return(
+					&DTO.Product{ID: 2, UniqueCode: "lkjhgf", Name: "laptop", Size: 2, Serialized: true},
+					nil,
+				)
+			},
+			expectedStatusCode:   201,
+			expectedResponseBody: "{\"id\":2,\"unique_code\":\"lkjhgf\",\"name\":\"laptop\",\"size\":2,\"serialized\":true}",
+		},
+		{
+			name:        "Update - in stock",
+			path:        "/UpdateProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"olkiuj\", \"name\": \"chair\", \"size\": 3, \"serialized\": true}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().UpdateProduct(&DTO.ReqProduct{UniqueCode: "olkiuj", Name: "chair", Size: 3, Serialized: true}).Return(
+					nil,
+					service.ErrProductInStock,
+				)
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: "{\"error\":\"product is in stock\"}",
+		},
+		{
+			name:        "Delete - in stock",
+			path:        "/DeleteProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"stocked\"}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().DeleteProduct(&DTO.ReqGetProduct{UniqueCode: "stocked"}).Return(nil, service.ErrProductInStock)
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: "{\"error\":\"product is in stock\"}",
+		},
+		{
+			name:        "Delete",
+			path:        "/DeleteProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"olkiuj\"}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().DeleteProduct(&DTO.ReqGetProduct{UniqueCode: "olkiuj"}).Return(
+					&DTO.Product{ID: 1, UniqueCode: "olkiuj", Name: "chair", Size: 3},
+					nil,
+				)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: "{\"id\":1,\"unique_code\":\"olkiuj\",\"name\":\"chair\",\"size\":3,\"serialized\":false}",
+		},
+		{
+			name:        "Delete - non-existent product",
+			path:        "/DeleteProduct",
+			role:        admin,
+			requestBody: "{\"unique_code\": \"olkiuj\"}",
+			mockCatalogBehaviour: func(s mock_service.MockCatalogService) {
+				s.EXPECT().DeleteProduct(&DTO.ReqGetProduct{UniqueCode: "olkiuj"}).Return(nil, service.ErrNonExistProduct)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: "{\"error\":\"non-existent product\"}",
+		},
+	}
+
+	for _, test := range *testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := gin.New()
+
+			middleware := &MockAuthHandler{
+				AuthorizeFn: func(c *gin.Context) {
+					c.Set("id", "lol")
+					c.Set("login", "lol")
+					c.Set("role", test.role)
+				},
+			}
+
+			catalogService := mock_service.NewMockCatalogService(ctrl)
+			test.mockCatalogBehaviour(*catalogService)
+			handler := NewCatalogHandler(catalogService, middleware)
+			handler.Register(r)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, test.path, bytes.NewBufferString(test.requestBody))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
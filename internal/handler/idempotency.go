@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"example1/internal/service"
+	"example1/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+const maxIdempotencyKeyLength = 255
+
+var ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+type idempotencyHandler struct {
+	Service service.IdempotencyService
+	Logger  logger.Logger
+}
+
+func NewIdempotencyHandler(s service.IdempotencyService) IdempotencyHandler {
+	return &idempotencyHandler{
+		Service: s,
+		Logger:  logger.Get(),
+	}
+}
+
+type IdempotencyHandler interface {
+	Handle(c *gin.Context)
+}
+
+// Handle makes the handlers after it idempotent for requests with an Idempotency-Key header.
+// The first response for a key and user is stored and replayed for repeated requests, so the
+// request is handled only once. It must run after Authorize.
+func (h *idempotencyHandler) Handle(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+
+	h.Logger.Info("start handler Idempotency")
+
+	if len(key) > maxIdempotencyKeyLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidIdempotencyKey.Error()})
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	userID := currentUser(c).ID
+	request := append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...)
+	stored, err := h.Service.Claim(userID, key, request)
+	if err != nil {
+		h.Logger.Error(err)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrRequestInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternalError.Error()})
+		}
+		return
+	}
+
+	if stored != nil {
+		c.Data(stored.StatusCode, "application/json; charset=utf-8", stored.Response)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	defer func() {
+		// A panic is recovered above this handler, so the key is released before passing it on,
+		// otherwise retries would see the request in progress until the key expires.
+		if p := recover(); p != nil {
+			if err := h.Service.Release(userID, key); err != nil {
+				h.Logger.Error(err)
+			}
+			panic(p)
+		}
+	}()
+	c.Next()
+
+	// Server errors are not stored so that the request can be retried with the same key.
+	if recorder.Status() >= http.StatusInternalServerError {
+		err = h.Service.Release(userID, key)
+	} else {
+		err = h.Service.SaveResponse(userID, key, recorder.Status(), recorder.body.Bytes())
+	}
+	if err != nil {
+		h.Logger.Error(err)
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
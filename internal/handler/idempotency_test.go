@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"example1/internal/model"
+	"example1/internal/service"
+	mock_service "example1/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdempotencyHandler_Handle(t *testing.T) {
+	type mockIdempotencyBehaviour func(s mock_service.MockIdempotencyService, request []byte)
+
+	testTable := &[]struct {
+		name                     string
+		key                      string
+		requestBody              string
+		handlerStatusCode        int
+		handlerPanics            bool
+		mockIdempotencyBehaviour mockIdempotencyBehaviour
+		expectedStatusCode       int
+		expectedResponseBody     string
+		expectedCalls            int
+	}{
+		{
+			name:              "First request",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, nil)
+				s.EXPECT().SaveResponse("lol", "a", 200, []byte("{\"calls\":1}")).Return(nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: "{\"calls\":1}",
+			expectedCalls:        1,
+		},
+		{
+			name:              "Replayed",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(&model.IdempotentRequest{
+					StatusCode: 201,
+					Response:   []byte("{\"calls\":1}"),
+				}, nil)
+			},
+			expectedStatusCode:   201,
+			expectedResponseBody: "{\"calls\":1}",
+			expectedCalls:        0,
+		},
+		{
+			name:              "Key reused with another body",
+			key:               "a",
+			requestBody:       "{\"id\": [2]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, service.ErrIdempotencyKeyReused)
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: "{\"error\":\"idempotency key reused with a different request\"}",
+			expectedCalls:        0,
+		},
+		{
+			name:              "In progress",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, service.ErrRequestInProgress)
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: "{\"error\":\"request with this idempotency key is in progress\"}",
+			expectedCalls:        0,
+		},
+		{
+			name:              "Claim failed",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: "{\"error\":\"internal error\"}",
+			expectedCalls:        0,
+		},
+		{
+			name:              "Server error released",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 500,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, nil)
+				s.EXPECT().Release("lol", "a").Return(nil)
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: "{\"calls\":1}",
+			expectedCalls:        1,
+		},
+		{
+			name:              "Handler panicked",
+			key:               "a",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			handlerPanics:     true,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+				s.EXPECT().Claim("lol", "a", request).Return(nil, nil)
+				s.EXPECT().Release("lol", "a").Return(nil)
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: "",
+			expectedCalls:        1,
+		},
+		{
+			name:              "Too long key",
+			key:               string(bytes.Repeat([]byte("a"), maxIdempotencyKeyLength+1)),
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: "{\"error\":\"invalid idempotency key\"}",
+			expectedCalls:        0,
+		},
+		{
+			name:              "Without key",
+			requestBody:       "{\"id\": [1]}",
+			handlerStatusCode: 200,
+			mockIdempotencyBehaviour: func(s mock_service.MockIdempotencyService, request []byte) {
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: "{\"calls\":1}",
+			expectedCalls:        1,
+		},
+	}
+
+	for _, test := range *testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			idempotencyService := mock_service.NewMockIdempotencyService(ctrl)
+			test.mockIdempotencyBehaviour(*idempotencyService, []byte("POST /ReserveProduct\n"+test.requestBody))
+
+			calls := 0
+			r := gin.New()
+			r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}))
+			h := NewIdempotencyHandler(idempotencyService)
+			r.POST("/ReserveProduct", func(c *gin.Context) { c.Set("id", "lol") }, h.Handle, func(c *gin.Context) {
+				calls++
+				if test.handlerPanics {
+					panic("handler failed")
+				}
+				c.AbortWithStatusJSON(test.handlerStatusCode, gin.H{"calls": calls})
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/ReserveProduct", bytes.NewBufferString(test.requestBody))
+			if test.key != "" {
+				req.Header.Set(idempotencyKeyHeader, test.key)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
@@ -15,11 +15,12 @@ var InvalidBodyError = errors.New("invalid body")
 type productHandler struct {
 	ProductService service.ProductService
 	Middleware     AuthHandler
+	Idempotency    IdempotencyHandler
 	Logger         logger.Logger
 }
 
-func NewProductHandler(s service.ProductService, auth AuthHandler) ProductHandler {
-	return &productHandler{s, auth, logger.Get()}
+func NewProductHandler(s service.ProductService, auth AuthHandler, idempotency IdempotencyHandler) ProductHandler {
+	return &productHandler{s, auth, idempotency, logger.Get()}
 }
 
 type ProductHandler interface {
@@ -27,8 +28,17 @@ type ProductHandler interface {
 }
 
 func (h *productHandler) Register(router *gin.Engine) {
-	router.Handle(http.MethodPost, "/ReserveProduct", h.Middleware.Authorize, h.ReserveProducts)
-	router.Handle(http.MethodPost, "/FreeReservation", h.Middleware.Authorize, h.FreeReservation)
+	router.Handle(http.MethodPost, "/ReserveProduct", h.Middleware.Authorize, h.Idempotency.Handle, h.ReserveProducts)
+	router.Handle(http.MethodPost, "/QuoteReservation", h.Middleware.Authorize, h.QuoteReservation)
+	router.Handle(http.MethodPost, "/FreeReservation", h.Middleware.Authorize, h.Idempotency.Handle, h.FreeReservation)
+	router.Handle(http.MethodPost, "/ExtendReservation", h.Middleware.Authorize, h.ExtendReservation)
+	router.Handle(http.MethodPost, "/ConfirmReservation", h.Middleware.Authorize, h.ConfirmReservation)
+	router.Handle(http.MethodPost, "/ShipReservation", h.Middleware.Authorize, h.ShipReservation)
+	router.Handle(http.MethodPost, "/CancelReservation", h.Middleware.Authorize, h.CancelReservation)
+	router.Handle(http.MethodPost, "/GetReservation", h.Middleware.Authorize, h.GetReservation)
+	router.Handle(http.MethodPost, "/ListReservations", h.Middleware.Authorize, h.ListReservations)
+	router.Handle(http.MethodPost, "/ListBackorders", h.Middleware.Authorize, h.ListBackorders)
+	router.Handle(http.MethodPost, "/CancelBackorder", h.Middleware.Authorize, h.CancelBackorder)
 }
 
 func (h *productHandler) ReserveProducts(c *gin.Context) {
@@ -55,14 +65,14 @@ func (h *productHandler) ReserveProducts(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
 		return
 	}
-	if req.WarehouseID == 0 || len(req.UniqueCodes) == 0 || len(req.Counts) == 0 ||
+	if (req.WarehouseID == 0 && !req.Split) || len(req.UniqueCodes) == 0 || len(req.Counts) == 0 ||
 		len(req.UniqueCodes) != len(req.Counts) {
 		h.Logger.Error(LackOfDataError)
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
 		return
 	}
 
-	res, err := h.ProductService.Reserve(req)
+	res, err := h.ProductService.Reserve(req, currentUser(c))
 	if err != nil {
 		h.Logger.Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -70,11 +80,15 @@ func (h *productHandler) ReserveProducts(c *gin.Context) {
 	}
 
 	if len(res.Unsuccessful) == 0 {
-		c.AbortWithStatusJSON(http.StatusOK, gin.H{"successful": res.Successful})
+		body := gin.H{"successful": res.Successful}
+		if len(res.Backordered) != 0 {
+			body["backordered"] = res.Backordered
+		}
+		c.AbortWithStatusJSON(http.StatusOK, body)
 		return
 	}
 
-	if len(res.Successful) == 0 {
+	if len(res.Successful) == 0 && len(res.Backordered) == 0 {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"unsuccessful": res.Unsuccessful, "errors": res.Errors})
 		return
 	}
@@ -82,6 +96,43 @@ func (h *productHandler) ReserveProducts(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusMultiStatus, res)
 }
 
+// QuoteReservation answers whether a /ReserveProduct request with the same body could be
+// reserved now, without reserving anything.
+func (h *productHandler) QuoteReservation(c *gin.Context) {
+	h.Logger.Info("start handler QuoteReservation")
+
+	if !allow(c, productWorker, admin) {
+		return
+	}
+
+	req := &DTO.ReqReserveProduct{}
+	err := c.BindJSON(req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+	if (req.WarehouseID == 0 && !req.Split) || len(req.UniqueCodes) == 0 || len(req.Counts) == 0 ||
+		len(req.UniqueCodes) != len(req.Counts) {
+		h.Logger.Error(LackOfDataError)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.Quote(req)
+	if err != nil {
+		h.Logger.Error(err)
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrInternal) {
+			status = http.StatusInternalServerError
+		}
+		c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
 func (h *productHandler) FreeReservation(c *gin.Context) {
 	h.Logger.Info("start handler FreeReservation")
 
@@ -110,14 +161,14 @@ func (h *productHandler) FreeReservation(c *gin.Context) {
 		return
 	}
 
-	res, err := h.ProductService.FreeReservation(req)
+	res, err := h.ProductService.FreeReservation(req, currentUser(c))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	if len(res.Unsuccessful) == 0 {
-		c.AbortWithStatusJSON(http.StatusOK, gin.H{"successful": res.Successful})
+		c.AbortWithStatusJSON(http.StatusOK, gin.H{"successful": res.Successful, "restored": res.Restored})
 		return
 	}
 	if len(res.Successful) == 0 {
@@ -127,3 +178,233 @@ func (h *productHandler) FreeReservation(c *gin.Context) {
 
 	c.AbortWithStatusJSON(http.StatusMultiStatus, res)
 }
+
+func (h *productHandler) ExtendReservation(c *gin.Context) {
+	h.Logger.Info("start handler ExtendReservation")
+
+	temp, ok := c.Get("role")
+	if !ok {
+		h.Logger.Error("unauthorized")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userRole := temp.(int32)
+	if userRole != productWorker && userRole != admin {
+		h.Logger.Error("forbidden")
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	req := &DTO.ReqExtendReservation{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	if req.ID == 0 || req.TTL == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.ExtendReservation(req)
+	if err != nil {
+		if errors.Is(err, service.ErrInternal) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *productHandler) ConfirmReservation(c *gin.Context) {
+	h.Logger.Info("start handler ConfirmReservation")
+
+	if !allow(c, productWorker, admin) {
+		return
+	}
+	h.changeStatus(c, h.ProductService.ConfirmReservation)
+}
+
+func (h *productHandler) ShipReservation(c *gin.Context) {
+	h.Logger.Info("start handler ShipReservation")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+	h.changeStatus(c, h.ProductService.ShipReservation)
+}
+
+func (h *productHandler) CancelReservation(c *gin.Context) {
+	h.Logger.Info("start handler CancelReservation")
+
+	if !allow(c, productWorker, admin) {
+		return
+	}
+	h.changeStatus(c, h.ProductService.CancelReservation)
+}
+
+func (h *productHandler) GetReservation(c *gin.Context) {
+	h.Logger.Info("start handler GetReservation")
+
+	if !allow(c, productWorker, warehouseWorker, admin) {
+		return
+	}
+
+	req := &DTO.ReqGetReservation{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	if req.ID == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.GetReservation(req)
+	if err != nil {
+		h.Logger.Error(err)
+		switch {
+		case errors.Is(err, service.ErrNonExistReservationId):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *productHandler) ListReservations(c *gin.Context) {
+	h.Logger.Info("start handler ListReservations")
+
+	if !allow(c, productWorker, warehouseWorker, admin) {
+		return
+	}
+
+	req := &DTO.ReqListReservations{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.ListReservations(req)
+	if err != nil {
+		h.Logger.Error(err)
+		if errors.Is(err, service.ErrInternal) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *productHandler) ListBackorders(c *gin.Context) {
+	h.Logger.Info("start handler ListBackorders")
+
+	if !allow(c, productWorker, admin) {
+		return
+	}
+
+	req := &DTO.ReqListBackorders{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.ListBackorders(req, currentUser(c))
+	if err != nil {
+		h.Logger.Error(err)
+		if errors.Is(err, service.ErrInternal) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *productHandler) CancelBackorder(c *gin.Context) {
+	h.Logger.Info("start handler CancelBackorder")
+
+	if !allow(c, productWorker, admin) {
+		return
+	}
+
+	req := &DTO.ReqCancelBackorder{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	if req.ID == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.ProductService.CancelBackorder(req, currentUser(c))
+	if err != nil {
+		h.Logger.Error(err)
+		switch {
+		case errors.Is(err, service.ErrNonExistBackorderId):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrForbidden):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrBackorderNotWaiting):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+// changeStatus binds a status change request and responds with the result of change.
+func (h *productHandler) changeStatus(c *gin.Context,
+	change func(*DTO.ReqChangeReservationStatus, *service.AuthInfo) (*DTO.ResChangeReservationStatus, error)) {
+	req := &DTO.ReqChangeReservationStatus{}
+	err := c.BindJSON(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": InvalidBodyError.Error()})
+		return
+	}
+
+	if req.ID == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := change(req, currentUser(c))
+	if err != nil {
+		h.Logger.Error(err)
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidTransition):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
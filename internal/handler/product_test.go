@@ -6,6 +6,7 @@ import (
 	"example1/internal/DTO"
 	"example1/internal/service"
 	mock_service "example1/internal/service/mocks"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
@@ -23,6 +24,10 @@ func (m *MockAuthHandler) Authorize(c *gin.Context) {
 	return
 }
 
+type MockIdempotencyHandler struct{}
+
+func (m *MockIdempotencyHandler) Handle(c *gin.Context) {}
+
 func TestProductHandler_Register(t *testing.T) {
 	type mockProductBehaviour func(s mock_service.MockProductService, product *DTO.ReqReserveProduct)
 	type mockAuthBehaviour func(c *gin.Context)
@@ -52,7 +57,7 @@ func TestProductHandler_Register(t *testing.T) {
 				},
 			},
 			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
-				s.EXPECT().Reserve(product).Return(
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
 					&DTO.ResReserveProduct{
 						Successful: []DTO.Successful{
 							{
@@ -95,7 +100,7 @@ func TestProductHandler_Register(t *testing.T) {
 				},
 			},
 			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
-				s.EXPECT().Reserve(product).Return(
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
 					&DTO.ResReserveProduct{
 						Successful: []DTO.Successful{},
 						Unsuccessful: []string{
@@ -135,7 +140,7 @@ func TestProductHandler_Register(t *testing.T) {
 				},
 			},
 			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
-				s.EXPECT().Reserve(product).Return(
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
 					nil,
 					errors.New("warehouse is unavailable"),
 				)
@@ -168,7 +173,7 @@ func TestProductHandler_Register(t *testing.T) {
 				},
 			},
 			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
-				s.EXPECT().Reserve(product).Return(
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
 					&DTO.ResReserveProduct{
 						Successful: []DTO.Successful{
 							{
@@ -197,6 +202,99 @@ func TestProductHandler_Register(t *testing.T) {
 			expectedStatusCode:   207,
 		},
 
+		{
+			name: "Backordered",
+			requestBody: "{\n    \"warehouse_id\": 2,\n    \"unique_codes\": [\n        \"olkiuj\",\n        \"tghyuj\"\n    ],\n" +
+				"    \"counts\": [\n        10,\n        10000\n    ],\n    \"backorder\": true\n}",
+			reqDTO: DTO.ReqReserveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{
+					"olkiuj",
+					"tghyuj",
+				},
+				Counts: []int{
+					10,
+					10000,
+				},
+				Backorder: true,
+			},
+			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
+					&DTO.ResReserveProduct{
+						Successful: []DTO.Successful{
+							{
+								ID:         9,
+								UniqueCode: "olkiuj",
+							},
+						},
+						Backordered: []DTO.Backorder{
+							{
+								ID:          3,
+								WarehouseID: 2,
+								UniqueCode:  "tghyuj",
+								Count:       10000,
+								Status:      "waiting",
+								Owner:       "lol",
+							},
+						},
+						Unsuccessful: []string{},
+						Errors:       []string{},
+					},
+					nil,
+				)
+			},
+			mockAuthBehavior: func(c *gin.Context) {
+				c.Set("id", "lol")
+				c.Set("login", "lol")
+				c.Set("role", int32(0))
+			},
+			expectedResponseBody: "{\"backordered\":[{\"id\":3,\"warehouse_id\":2,\"unique_code\":\"tghyuj\",\"count\":10000," +
+				"\"status\":\"waiting\",\"owner\":\"lol\",\"created_at\":\"0001-01-01T00:00:00Z\"}]," +
+				"\"successful\":[{\"id\":9,\"unique_codes\":\"olkiuj\"}]}",
+			expectedStatusCode: 200,
+		},
+
+		{
+			name: "Atomic - rolled back",
+			requestBody: "{\n    \"warehouse_id\": 2,\n    \"unique_codes\": [\n        \"olkiuj\",\n        \"tghyuj\"\n    ],\n" +
+				"    \"counts\": [\n        1000,\n        10000\n    ],\n    \"atomic\": true\n}",
+			reqDTO: DTO.ReqReserveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{
+					"olkiuj",
+					"tghyuj",
+				},
+				Counts: []int{
+					1000,
+					10000,
+				},
+				Atomic: true,
+			},
+			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
+					&DTO.ResReserveProduct{
+						Successful: []DTO.Successful{},
+						Unsuccessful: []string{
+							"olkiuj",
+							"tghyuj",
+						},
+						Errors: []string{
+							"rolled back",
+							"not enough product",
+						},
+					},
+					nil,
+				)
+			},
+			mockAuthBehavior: func(c *gin.Context) {
+				c.Set("id", "lol")
+				c.Set("login", "lol")
+				c.Set("role", int32(2))
+			},
+			expectedResponseBody: "{\"errors\":[\"rolled back\",\"not enough product\"],\"unsuccessful\":[\"olkiuj\",\"tghyuj\"]}",
+			expectedStatusCode:   400,
+		},
+
 		{
 			name:        "Lack of data",
 			requestBody: "{\n    \"warehouse_id\": 2,\n    \"counts\": [\n        1000,\n        10000,\n        1000\n    ]\n}",
@@ -264,7 +362,7 @@ func TestProductHandler_Register(t *testing.T) {
 				},
 			},
 			mockProductBehaviour: func(s mock_service.MockProductService, product *DTO.ReqReserveProduct) {
-				s.EXPECT().Reserve(product).Return(
+				s.EXPECT().Reserve(product, gomock.Any()).Return(
 					nil,
 					service.ErrInvalidWarehouse,
 				)
@@ -317,7 +415,7 @@ func TestProductHandler_Register(t *testing.T) {
 
 			productService := mock_service.NewMockProductService(ctrl)
 			test.mockProductBehaviour(*productService, &test.reqDTO)
-			handler := NewProductHandler(productService, middleware)
+			handler := NewProductHandler(productService, middleware, &MockIdempotencyHandler{})
 			handler.Register(r)
 
 			w := httptest.NewRecorder()
@@ -329,3 +427,117 @@ func TestProductHandler_Register(t *testing.T) {
 		})
 	}
 }
+
+func TestProductHandler_ChangeStatus(t *testing.T) {
+	type mockProductBehaviour func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus)
+
+	testTable := &[]struct {
+		name                 string
+		path                 string
+		role                 int32
+		requestBody          string
+		mockProductBehaviour mockProductBehaviour
+		reqDTO               DTO.ReqChangeReservationStatus
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "OK",
+			path:        "/ConfirmReservation",
+			role:        productWorker,
+			requestBody: "{\"id\": 6}",
+			reqDTO:      DTO.ReqChangeReservationStatus{ID: 6},
+			mockProductBehaviour: func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus) {
+				s.EXPECT().ConfirmReservation(req, gomock.Any()).Return(
+					&DTO.ResChangeReservationStatus{ID: 6, Status: "confirmed"},
+					nil,
+				)
+			},
+			expectedResponseBody: "{\"id\":6,\"status\":\"confirmed\"}",
+			expectedStatusCode:   200,
+		},
+
+		{
+			name:        "Invalid transition",
+			path:        "/ShipReservation",
+			role:        warehouseWorker,
+			requestBody: "{\"id\": 6}",
+			reqDTO:      DTO.ReqChangeReservationStatus{ID: 6},
+			mockProductBehaviour: func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus) {
+				s.EXPECT().ShipReservation(req, gomock.Any()).Return(
+					nil,
+					fmt.Errorf("%w from reserved to shipped", service.ErrInvalidTransition),
+				)
+			},
+			expectedResponseBody: "{\"error\":\"invalid status transition from reserved to shipped\"}",
+			expectedStatusCode:   409,
+		},
+
+		{
+			name:        "Non-existent reservation",
+			path:        "/CancelReservation",
+			role:        admin,
+			requestBody: "{\"id\": 81}",
+			reqDTO:      DTO.ReqChangeReservationStatus{ID: 81},
+			mockProductBehaviour: func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus) {
+				s.EXPECT().CancelReservation(req, gomock.Any()).Return(
+					nil,
+					service.ErrNonExistReservationId,
+				)
+			},
+			expectedResponseBody: "{\"error\":\"non-existent reservation id\"}",
+			expectedStatusCode:   400,
+		},
+
+		{
+			name:        "Lack of data",
+			path:        "/CancelReservation",
+			role:        admin,
+			requestBody: "{}",
+			mockProductBehaviour: func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus) {
+			},
+			expectedResponseBody: "{\"error\":\"lack of data\"}",
+			expectedStatusCode:   400,
+		},
+
+		{
+			name:        "Forbidden",
+			path:        "/ShipReservation",
+			role:        productWorker,
+			requestBody: "{\"id\": 6}",
+			mockProductBehaviour: func(s mock_service.MockProductService, req *DTO.ReqChangeReservationStatus) {
+			},
+			expectedResponseBody: "{\"error\":\"forbidden\"}",
+			expectedStatusCode:   403,
+		},
+	}
+
+	for _, test := range *testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := gin.New()
+
+			middleware := &MockAuthHandler{
+				AuthorizeFn: func(c *gin.Context) {
+					c.Set("id", "lol")
+					c.Set("login", "lol")
+					c.Set("role", test.role)
+				},
+			}
+
+			productService := mock_service.NewMockProductService(ctrl)
+			test.mockProductBehaviour(*productService, &test.reqDTO)
+			handler := NewProductHandler(productService, middleware, &MockIdempotencyHandler{})
+			handler.Register(r)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, test.path, bytes.NewBufferString(test.requestBody))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -32,6 +32,30 @@ type WarehouseHandler interface {
 
 func (h *warehouseHandler) Register(router *gin.Engine) {
 	router.Handle(http.MethodPost, "/GetAllProducts", h.Middleware.Authorize, h.GetAllProducts)
+	router.Handle(http.MethodPost, "/GetStockMovements", h.Middleware.Authorize, h.GetStockMovements)
+	router.Handle(http.MethodPost, "/ReceiveProduct", h.Middleware.Authorize, h.ReceiveProduct)
+	router.Handle(http.MethodPost, "/TransferProduct", h.Middleware.Authorize, h.TransferProduct)
+	router.Handle(http.MethodPost, "/ReceiveTransfer", h.Middleware.Authorize, h.ReceiveTransfer)
+	router.Handle(http.MethodPost, "/SetReorderThreshold", h.Middleware.Authorize, h.SetReorderThreshold)
+	router.Handle(http.MethodPost, "/GetLowStock", h.Middleware.Authorize, h.GetLowStock)
+	router.Handle(http.MethodPost, "/Reconcile", h.Middleware.Authorize, h.Reconcile)
+	router.Handle(http.MethodPost, "/CreateWarehouse", h.Middleware.Authorize, h.CreateWarehouse)
+	router.Handle(http.MethodPost, "/UpdateWarehouse", h.Middleware.Authorize, h.UpdateWarehouse)
+	router.Handle(http.MethodPost, "/GetWarehouse", h.Middleware.Authorize, h.GetWarehouse)
+	router.Handle(http.MethodPost, "/ListWarehouses", h.Middleware.Authorize, h.ListWarehouses)
+	router.Handle(http.MethodPost, "/SetWarehouseAvailability", h.Middleware.Authorize, h.SetWarehouseAvailability)
+	router.Handle(http.MethodPost, "/GetUtilization", h.Middleware.Authorize, h.GetUtilization)
+	router.Handle(http.MethodPost, "/GetAvailability", h.Middleware.Authorize, h.GetAvailability)
+	router.Handle(http.MethodPost, "/GetExpiringLots", h.Middleware.Authorize, h.GetExpiringLots)
+	router.Handle(http.MethodPost, "/GetSerialNumber", h.Middleware.Authorize, h.GetSerialNumber)
+	router.Handle(http.MethodPost, "/AdjustStock", h.Middleware.Authorize, h.AdjustStock)
+	router.Handle(http.MethodPost, "/ApproveAdjustment", h.Middleware.Authorize, h.ApproveAdjustment)
+	router.Handle(http.MethodPost, "/RejectAdjustment", h.Middleware.Authorize, h.RejectAdjustment)
+	router.Handle(http.MethodPost, "/ListAdjustments", h.Middleware.Authorize, h.ListAdjustments)
+	router.Handle(http.MethodPost, "/OpenCycleCount", h.Middleware.Authorize, h.OpenCycleCount)
+	router.Handle(http.MethodPost, "/SubmitCycleCount", h.Middleware.Authorize, h.SubmitCycleCount)
+	router.Handle(http.MethodPost, "/GetCycleCount", h.Middleware.Authorize, h.GetCycleCount)
+	router.Handle(http.MethodPost, "/CloseCycleCount", h.Middleware.Authorize, h.CloseCycleCount)
 }
 
 func (h *warehouseHandler) GetAllProducts(c *gin.Context) {
@@ -65,9 +89,592 @@ func (h *warehouseHandler) GetAllProducts(c *gin.Context) {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternalError.Error()})
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetStockMovements(c *gin.Context) {
+	h.Logger.Info("start handler GetStockMovements")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqGetMovements{}
+	err := c.BindJSON(&req)
+	if err != nil || req.WarehouseID == 0 || req.UniqueCode == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetMovements(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) ReceiveProduct(c *gin.Context) {
+	h.Logger.Info("start handler ReceiveProduct")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqReceiveProduct{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+	if req.WarehouseID == 0 || len(req.UniqueCodes) == 0 || len(req.UniqueCodes) != len(req.Counts) ||
+		(len(req.LotNumbers) != 0 && len(req.LotNumbers) != len(req.UniqueCodes)) ||
+		(len(req.ExpiryDates) != 0 && len(req.ExpiryDates) != len(req.UniqueCodes)) ||
+		(len(req.SerialNumbers) != 0 && len(req.SerialNumbers) != len(req.UniqueCodes)) {
+		h.Logger.Error(LackOfDataError)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.Service.Receive(&req, currentUser(c))
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(res.Unsuccessful) == 0 {
+		c.AbortWithStatusJSON(http.StatusOK, gin.H{"successful": res.Successful})
+		return
+	}
+
+	if len(res.Successful) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"unsuccessful": res.Unsuccessful, "errors": res.Errors})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusMultiStatus, res)
+}
+
+func (h *warehouseHandler) TransferProduct(c *gin.Context) {
+	h.Logger.Info("start handler TransferProduct")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqTransferProduct{}
+	err := c.BindJSON(&req)
+	if err != nil || req.FromWarehouseID == 0 || req.ToWarehouseID == 0 || req.UniqueCode == "" || req.Count == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.Transfer(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) ReceiveTransfer(c *gin.Context) {
+	h.Logger.Info("start handler ReceiveTransfer")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqReceiveTransfer{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.ReceiveTransfer(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) SetReorderThreshold(c *gin.Context) {
+	h.Logger.Info("start handler SetReorderThreshold")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqSetReorderThreshold{}
+	err := c.BindJSON(&req)
+	if err != nil || req.WarehouseID == 0 || req.UniqueCode == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.SetReorderThreshold(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetLowStock(c *gin.Context) {
+	h.Logger.Info("start handler GetLowStock")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	res, err := h.Service.GetLowStock()
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) Reconcile(c *gin.Context) {
+	h.Logger.Info("start handler Reconcile")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqReconcile{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.Reconcile(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) CreateWarehouse(c *gin.Context) {
+	h.Logger.Info("start handler CreateWarehouse")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqCreateWarehouse{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.CreateWarehouse(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, res)
+}
+
+func (h *warehouseHandler) UpdateWarehouse(c *gin.Context) {
+	h.Logger.Info("start handler UpdateWarehouse")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqUpdateWarehouse{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.UpdateWarehouse(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetWarehouse(c *gin.Context) {
+	h.Logger.Info("start handler GetWarehouse")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqGetWarehouse{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetWarehouse(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) ListWarehouses(c *gin.Context) {
+	h.Logger.Info("start handler ListWarehouses")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	res, err := h.Service.ListWarehouses()
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) SetWarehouseAvailability(c *gin.Context) {
+	h.Logger.Info("start handler SetWarehouseAvailability")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqSetWarehouseAvailability{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.SetAvailability(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetUtilization(c *gin.Context) {
+	h.Logger.Info("start handler GetUtilization")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	res, err := h.Service.GetUtilization()
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetAvailability(c *gin.Context) {
+	h.Logger.Info("start handler GetAvailability")
+
+	if !allow(c, productWorker, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqGetAvailability{}
+	err := c.BindJSON(&req)
+	if err != nil || len(req.UniqueCodes) == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetAvailability(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetExpiringLots(c *gin.Context) {
+	h.Logger.Info("start handler GetExpiringLots")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqGetExpiringLots{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetExpiringLots(&req)
+	if err != nil {
+		h.abortWithError(c, err)
 		return
 	}
 
 	c.AbortWithStatusJSON(http.StatusOK, res)
 }
+
+func (h *warehouseHandler) GetSerialNumber(c *gin.Context) {
+	h.Logger.Info("start handler GetSerialNumber")
+
+	if !allow(c, productWorker, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqGetSerialNumber{}
+	err := c.BindJSON(&req)
+	if err != nil || req.SerialNumber == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetSerialNumber(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) AdjustStock(c *gin.Context) {
+	h.Logger.Info("start handler AdjustStock")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqAdjustStock{}
+	err := c.BindJSON(&req)
+	if err != nil || req.WarehouseID == 0 || req.UniqueCode == "" || req.Delta == 0 || req.Reason == "" {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.AdjustStock(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, res)
+}
+
+func (h *warehouseHandler) ApproveAdjustment(c *gin.Context) {
+	h.Logger.Info("start handler ApproveAdjustment")
+
+	if !allow(c, admin) {
+		return
+	}
+	h.reviewAdjustment(c, h.Service.ApproveAdjustment)
+}
+
+func (h *warehouseHandler) RejectAdjustment(c *gin.Context) {
+	h.Logger.Info("start handler RejectAdjustment")
+
+	if !allow(c, admin) {
+		return
+	}
+	h.reviewAdjustment(c, h.Service.RejectAdjustment)
+}
+
+func (h *warehouseHandler) reviewAdjustment(c *gin.Context,
+	review func(*DTO.ReqReviewAdjustment, *service.AuthInfo) (*DTO.Adjustment, error)) {
+	req := DTO.ReqReviewAdjustment{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := review(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) ListAdjustments(c *gin.Context) {
+	h.Logger.Info("start handler ListAdjustments")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqListAdjustments{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.ListAdjustments(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) OpenCycleCount(c *gin.Context) {
+	h.Logger.Info("start handler OpenCycleCount")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqOpenCycleCount{}
+	err := c.BindJSON(&req)
+	if err != nil || req.WarehouseID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.OpenCycleCount(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, res)
+}
+
+func (h *warehouseHandler) SubmitCycleCount(c *gin.Context) {
+	h.Logger.Info("start handler SubmitCycleCount")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqSubmitCycleCount{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+	if req.ID == 0 || len(req.UniqueCodes) == 0 || len(req.UniqueCodes) != len(req.Counts) {
+		h.Logger.Error(LackOfDataError)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LackOfDataError.Error()})
+		return
+	}
+
+	res, err := h.Service.SubmitCycleCount(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) GetCycleCount(c *gin.Context) {
+	h.Logger.Info("start handler GetCycleCount")
+
+	if !allow(c, warehouseWorker, admin) {
+		return
+	}
+
+	req := DTO.ReqGetCycleCount{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.GetCycleCount(&req)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+func (h *warehouseHandler) CloseCycleCount(c *gin.Context) {
+	h.Logger.Info("start handler CloseCycleCount")
+
+	if !allow(c, admin) {
+		return
+	}
+
+	req := DTO.ReqCloseCycleCount{}
+	err := c.BindJSON(&req)
+	if err != nil || req.ID == 0 {
+		h.Logger.Error(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidBody.Error()})
+		return
+	}
+
+	res, err := h.Service.CloseCycleCount(&req, currentUser(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, res)
+}
+
+// abortWithError responds with a service error, hiding the details of internal errors.
+func (h *warehouseHandler) abortWithError(c *gin.Context, err error) {
+	h.Logger.Error(err)
+	switch {
+	case errors.Is(err, service.ErrInternal):
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternalError.Error()})
+	case errors.Is(err, service.ErrNonExistWarehouse), errors.Is(err, service.ErrNonExistSerialNumber),
+		errors.Is(err, service.ErrNonExistAdjustmentId), errors.Is(err, service.ErrNonExistCycleCountId):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrWarehouseExists), errors.Is(err, service.ErrAdjustmentNotPending),
+		errors.Is(err, service.ErrCycleCountOpen), errors.Is(err, service.ErrCycleCountNotOpen),
+		errors.Is(err, service.ErrCycleCountClosed), errors.Is(err, service.ErrCountNotSettled):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
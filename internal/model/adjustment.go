@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApplied  = "applied"
+	AdjustmentRejected = "rejected"
+)
+
+// AdjustmentCycleCount is the reason code of adjustments made by closing a cycle count.
+const AdjustmentCycleCount = "cycle-count"
+
+// Adjustment is a correction of the stock of a product in a warehouse outside of receipts,
+// reservations and transfers, such as a write-off of damaged stock. Delta is added to both
+// total_count and left_count once the adjustment is applied. An adjustment of a serialized
+// product lists the units it adds or writes off. Lots are the lots a write-off was taken from
+// and are only known when the adjustment is applied.
+type Adjustment struct {
+	ID            int        `json:"id"`
+	WarehouseID   int        `json:"warehouse_id"`
+	ProductCode   string     `json:"product_code"`
+	Delta         int        `json:"delta"`
+	Reason        string     `json:"reason"`
+	Note          string     `json:"note"`
+	SerialNumbers []string   `json:"serial_numbers"`
+	Status        string     `json:"status"`
+	CreatedBy     string     `json:"created_by"`
+	ReviewedBy    string     `json:"reviewed_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	Lots          []LotCount `json:"lots"`
+}
+
+// AdjustmentFilter selects adjustments to list. Zero fields match any adjustment. Only
+// adjustments with an ID greater than Cursor are listed, at most Limit of them.
+type AdjustmentFilter struct {
+	WarehouseID int
+	ProductCode string
+	Status      string
+	Cursor      int
+	Limit       int
+}
+
+// IsAdjustmentStatus reports whether s is an adjustment status.
+func IsAdjustmentStatus(s string) bool {
+	switch s {
+	case AdjustmentPending, AdjustmentApplied, AdjustmentRejected:
+		return true
+	}
+	return false
+}
+
+// AppliedBy returns the user the adjustment is applied by: the admin who approved it, or the
+// user who made it if it did not need approval.
+func (a *Adjustment) AppliedBy() string {
+	if a.ReviewedBy != "" {
+		return a.ReviewedBy
+	}
+	return a.CreatedBy
+}
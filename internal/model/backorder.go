@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	BackorderWaiting   = "waiting"
+	BackorderFulfilled = "fulfilled"
+	BackorderCancelled = "cancelled"
+)
+
+// Backorder is a reservation line that could not be met from stock and waits for it. TTL is
+// the lifetime in minutes of the reservation made once the backorder is fulfilled, zero if it
+// never expires.
+type Backorder struct {
+	ID            int       `json:"id"`
+	WarehouseID   int       `json:"warehouse_id"`
+	ProductCode   string    `json:"product_code"`
+	Count         int       `json:"count"`
+	TTL           int       `json:"ttl"`
+	Status        string    `json:"status"`
+	UserID        string    `json:"user_id"`
+	ReservationID int       `json:"reservation_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BackorderFilter selects backorders to list. Zero fields match any backorder. Only
+// backorders with an ID greater than Cursor are listed, at most Limit of them.
+type BackorderFilter struct {
+	WarehouseID int
+	ProductCode string
+	UserID      string
+	Status      string
+	Cursor      int
+	Limit       int
+}
+
+// IsBackorderStatus reports whether s is a backorder status.
+func IsBackorderStatus(s string) bool {
+	switch s {
+	case BackorderWaiting, BackorderFulfilled, BackorderCancelled:
+		return true
+	}
+	return false
+}
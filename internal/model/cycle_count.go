@@ -0,0 +1,65 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	CycleCountOpen    = "open"
+	CycleCountClosing = "closing"
+	CycleCountClosed  = "closed"
+)
+
+const (
+	CountLineCounted  = "counted"
+	CountLineMatched  = "matched"
+	CountLineAdjusted = "adjusted"
+	CountLineSkipped  = "skipped"
+)
+
+// CycleCount is a physical count of the stock of a warehouse, limited to ProductCodes if any.
+// Products are counted while it is open. Closing it stops counting and settles the counted
+// lines one by one; it stays closing until all of them are settled. Only one count that is not
+// closed can exist in a warehouse at a time.
+type CycleCount struct {
+	ID           int              `json:"id"`
+	WarehouseID  int              `json:"warehouse_id"`
+	ProductCodes []string         `json:"product_codes"`
+	Status       string           `json:"status"`
+	CreatedBy    string           `json:"created_by"`
+	ClosedBy     string           `json:"closed_by"`
+	CreatedAt    time.Time        `json:"created_at"`
+	ClosedAt     *time.Time       `json:"closed_at"`
+	Lines        []CycleCountLine `json:"lines"`
+}
+
+// Covers reports whether the count includes the product.
+func (c *CycleCount) Covers(productCode string) bool {
+	if len(c.ProductCodes) == 0 {
+		return true
+	}
+	return slices.Contains(c.ProductCodes, productCode)
+}
+
+// CycleCountLine is the counted quantity of a product. Expected is total_count of the product
+// in the warehouse when it was counted, so stock moving later does not change the variance.
+// Adjusted is the part of the variance applied by AdjustmentID when the count was closed; a
+// shortage is only written off as far as it is not held by live reservations.
+type CycleCountLine struct {
+	ID           int       `json:"id"`
+	CycleCountID int       `json:"cycle_count_id"`
+	ProductCode  string    `json:"product_code"`
+	Counted      int       `json:"counted"`
+	Expected     int       `json:"expected"`
+	Status       string    `json:"status"`
+	Adjusted     int       `json:"adjusted"`
+	AdjustmentID int       `json:"adjustment_id"`
+	CountedBy    string    `json:"counted_by"`
+	CountedAt    time.Time `json:"counted_at"`
+}
+
+// Variance returns how much the counted quantity differs from the expected one.
+func (l *CycleCountLine) Variance() int {
+	return l.Counted - l.Expected
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// IdempotentRequest is a request made with an Idempotency-Key header. StatusCode is 0 while
+// the first request with the key is still being handled.
+type IdempotentRequest struct {
+	UserID      string    `json:"user_id"`
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	StatusCode  int       `json:"status_code"`
+	Response    []byte    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Lot is a quantity of a product received into a warehouse in one batch, identified in the
+// warehouse by its lot number. Lots are allocated first-expired, first-out; lots without an
+// expiry date go after all the dated ones, and expired lots are only written off. Stock
+// received without a lot number is not tracked in lots, so the lots of a product may hold less
+// than its warehouse_product row.
+type Lot struct {
+	ID          int        `json:"id"`
+	WarehouseID int        `json:"warehouse_id"`
+	ProductCode string     `json:"product_code"`
+	LotNumber   string     `json:"lot_number"`
+	ExpiresOn   *time.Time `json:"expires_on"`
+	TotalCount  int        `json:"total_count"`
+	LeftCount   int        `json:"left_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// LotCount is the part of a reservation or a transfer taken from one lot.
+type LotCount struct {
+	LotID     int        `json:"lot_id"`
+	LotNumber string     `json:"lot_number"`
+	ExpiresOn *time.Time `json:"expires_on"`
+	Count     int        `json:"count"`
+}
+
+// LotFilter selects the lots that still hold stock and expire on or before Before, including
+// those that have already expired. A zero WarehouseID matches every warehouse.
+type LotFilter struct {
+	WarehouseID int
+	Before      time.Time
+}
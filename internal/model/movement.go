@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	MovementReserve   = "reserve"
+	MovementFree      = "free"
+	MovementShip      = "ship"
+	MovementReceive   = "receive"
+	MovementAdjust    = "adjust"
+	MovementTransfer  = "transfer"
+	MovementReconcile = "reconcile"
+)
+
+// StockMovement is a change of the stock of a product in a warehouse. Delta is the change of
+// left_count and TotalDelta the change of total_count.
+type StockMovement struct {
+	ID            int       `json:"id"`
+	WarehouseID   int       `json:"warehouse_id"`
+	ProductCode   string    `json:"product_code"`
+	Delta         int       `json:"delta"`
+	TotalDelta    int       `json:"total_delta"`
+	Reason        string    `json:"reason"`
+	ReservationID int       `json:"reservation_id"`
+	UserID        string    `json:"user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MovementFilter selects the movements of a product in a warehouse made in [From, To). Zero
+// times leave the range open. Only movements with an ID greater than Cursor are listed, at
+// most Limit of them.
+type MovementFilter struct {
+	WarehouseID int
+	ProductCode string
+	From        time.Time
+	To          time.Time
+	Cursor      int
+	Limit       int
+}
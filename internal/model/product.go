@@ -1,17 +1,79 @@
 package model
 
+import "time"
+
 type Product struct {
-	ID         int    `json:"id"`
-	UniqueCode string `json:"unique_code"`
-	Name       string `json:"name"`
-	Size       int    `json:"size"`
-	Count      int    `json:"count"`
-	Left       int    `json:"left"`
+	ID         int        `json:"id"`
+	UniqueCode string     `json:"unique_code"`
+	Name       string     `json:"name"`
+	Size       int        `json:"size"`
+	Count      int        `json:"count"`
+	Left       int        `json:"left"`
+	Serialized bool       `json:"serialized"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+// ProductFilter selects catalog products to list. Deleted products are listed only with
+// WithDeleted set. Only products with an ID greater than Cursor are listed, at most Limit of them.
+type ProductFilter struct {
+	Name        string
+	WithDeleted bool
+	Cursor      int
+	Limit       int
 }
 
 type Reservation struct {
-	ID          int    `json:"id"`
-	WarehouseID int    `json:"warehouse_id"`
-	ProductCode string `json:"product_id"`
-	Count       int    `json:"count"`
+	ID            int        `json:"id"`
+	WarehouseID   int        `json:"warehouse_id"`
+	ProductCode   string     `json:"product_id"`
+	Count         int        `json:"count"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Status        string     `json:"status"`
+	UserID        string     `json:"user_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Lots          []LotCount `json:"lots"`
+	SerialNumbers []string   `json:"serial_numbers"`
+}
+
+// ReservationFilter selects reservations to list. Zero fields match any reservation. Only
+// reservations with an ID greater than Cursor are listed, at most Limit of them.
+type ReservationFilter struct {
+	WarehouseID int
+	ProductCode string
+	UserID      string
+	Status      string
+	Cursor      int
+	Limit       int
+}
+
+const (
+	StatusReserved  = "reserved"
+	StatusConfirmed = "confirmed"
+	StatusShipped   = "shipped"
+	StatusCancelled = "cancelled"
+)
+
+// transitions lists the statuses a reservation in a given status may move to.
+var transitions = map[string][]string{
+	StatusReserved:  {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusShipped, StatusCancelled},
+}
+
+// IsStatus reports whether s is a reservation status.
+func IsStatus(s string) bool {
+	switch s {
+	case StatusReserved, StatusConfirmed, StatusShipped, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether a reservation may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
 }
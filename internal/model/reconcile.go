@@ -0,0 +1,18 @@
+package model
+
+// Discrepancy is a warehouse_product row whose left_count differs from total_count minus the
+// count held by live reservations. Fixed is set once left_count has been corrected to Expected.
+type Discrepancy struct {
+	WarehouseID int    `json:"warehouse_id"`
+	ProductCode string `json:"product_code"`
+	TotalCount  int    `json:"total_count"`
+	LeftCount   int    `json:"left_count"`
+	Reserved    int    `json:"reserved"`
+	Expected    int    `json:"expected"`
+	Fixed       bool   `json:"fixed"`
+}
+
+// Difference returns how much left_count has to change to match the expected count.
+func (d *Discrepancy) Difference() int {
+	return d.Expected - d.LeftCount
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	SerialAvailable  = "available"
+	SerialReserved   = "reserved"
+	SerialShipped    = "shipped"
+	SerialInTransit  = "in_transit"
+	SerialWrittenOff = "written_off"
+)
+
+// SerialNumber is a unit of a serialized product. WarehouseID is the warehouse it is in, or
+// was shipped or written off from or is in transit from; ReservationID and TransferID are set
+// while it is reserved or in transit, and a shipped unit keeps the reservation it was shipped
+// with.
+type SerialNumber struct {
+	ID            int           `json:"id"`
+	ProductCode   string        `json:"product_code"`
+	SerialNumber  string        `json:"serial_number"`
+	WarehouseID   int           `json:"warehouse_id"`
+	Status        string        `json:"status"`
+	ReservationID int           `json:"reservation_id"`
+	TransferID    int           `json:"transfer_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	History       []SerialEvent `json:"history"`
+}
+
+// SerialEvent records a unit entering a status in a warehouse, together with the reservation
+// or transfer that moved it there.
+type SerialEvent struct {
+	Status        string    `json:"status"`
+	WarehouseID   int       `json:"warehouse_id"`
+	ReservationID int       `json:"reservation_id"`
+	TransferID    int       `json:"transfer_id"`
+	UserID        string    `json:"user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
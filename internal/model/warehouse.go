@@ -1,7 +1,151 @@
 package model
 
+import (
+	"strconv"
+	"time"
+)
+
+// Warehouse is a place where products are stocked. Capacity is the volume it can hold, the sum
+// of size × total_count of its products; zero means it is not limited.
 type Warehouse struct {
 	ID           int    `json:"id"`
 	Name         string `json:"name"`
 	Availability bool   `json:"availability"`
+	Capacity     int    `json:"capacity"`
+}
+
+// Utilization is the volume occupied by the products stocked in a warehouse and held for the
+// products in transit to it.
+type Utilization struct {
+	WarehouseID int    `json:"warehouse_id"`
+	Name        string `json:"name"`
+	Capacity    int    `json:"capacity"`
+	Occupied    int    `json:"occupied"`
+	InTransit   int    `json:"in_transit"`
+}
+
+// AvailabilityChange records who made a warehouse available or unavailable and why.
+type AvailabilityChange struct {
+	ID          int       `json:"id"`
+	WarehouseID int       `json:"warehouse_id"`
+	Available   bool      `json:"available"`
+	Reason      string    `json:"reason"`
+	UserID      string    `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WarehouseProduct struct {
+	ID               int    `json:"id"`
+	WarehouseID      int    `json:"warehouse_id"`
+	ProductCode      string `json:"product_code"`
+	TotalCount       int    `json:"total_count"`
+	LeftCount        int    `json:"left_count"`
+	ReorderThreshold int    `json:"reorder_threshold"`
+}
+
+// BelowThreshold reports whether the free stock is below the reorder threshold. A zero
+// threshold disables the check.
+func (p *WarehouseProduct) BelowThreshold() bool {
+	return p.LeftCount < p.ReorderThreshold
+}
+
+// ProductStock is the stock of a product in a warehouse together with its catalog data.
+// Reserved is the count held by live reservations.
+type ProductStock struct {
+	ProductCode string `json:"product_code"`
+	Name        string `json:"name"`
+	Size        int    `json:"size"`
+	TotalCount  int    `json:"total_count"`
+	LeftCount   int    `json:"left_count"`
+	Reserved    int    `json:"reserved"`
+}
+
+const (
+	SortByCode       = "unique_code"
+	SortByName       = "name"
+	SortByTotalCount = "total_count"
+	SortByLeftCount  = "left_count"
+	SortByReserved   = "reserved"
+)
+
+// IsProductStockSort reports whether products in a warehouse can be sorted by s.
+func IsProductStockSort(s string) bool {
+	switch s {
+	case SortByCode, SortByName, SortByTotalCount, SortByLeftCount, SortByReserved:
+		return true
+	}
+	return false
+}
+
+// SortValue returns the value of the field the product is sorted by.
+func (p *ProductStock) SortValue(sort string) string {
+	switch sort {
+	case SortByName:
+		return p.Name
+	case SortByTotalCount:
+		return strconv.Itoa(p.TotalCount)
+	case SortByLeftCount:
+		return strconv.Itoa(p.LeftCount)
+	case SortByReserved:
+		return strconv.Itoa(p.Reserved)
+	default:
+		return p.ProductCode
+	}
+}
+
+// ProductStockKey is the position of a product in a sorted list: the value it is sorted by and its code.
+type ProductStockKey struct {
+	Value       string
+	ProductCode string
+}
+
+// ProductStockFilter selects the products of a warehouse. Empty fields match any product; with
+// InStock set only products with free stock match. Products are ordered by Sort and then by
+// code, descending if Desc is set. Only products after the After key are listed, at most Limit of them.
+type ProductStockFilter struct {
+	WarehouseID int
+	CodePrefix  string
+	Name        string
+	InStock     bool
+	Sort        string
+	Desc        bool
+	After       *ProductStockKey
+	Limit       int
+}
+
+// Receipt is a quantity of a product received by a warehouse. With LotNumber set, the quantity
+// is added to that lot, which is created with the ExpiresOn date if it is new. A serialized
+// product is received with the serial number of every unit.
+type Receipt struct {
+	WarehouseID   int        `json:"warehouse_id"`
+	ProductCode   string     `json:"product_code"`
+	Count         int        `json:"count"`
+	LotNumber     string     `json:"lot_number"`
+	ExpiresOn     *time.Time `json:"expires_on"`
+	SerialNumbers []string   `json:"serial_numbers"`
+	UserID        string     `json:"user_id"`
+}
+
+const (
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+)
+
+// Transfer is a quantity of a product moved from one warehouse to another. An in-transit
+// transfer has left the source warehouse but has not been received by the destination yet.
+// Lots are the lots of the source warehouse the quantity was taken from, SerialNumbers the units
+// moved if the product is serialized.
+type Transfer struct {
+	ID              int        `json:"id"`
+	FromWarehouseID int        `json:"from_warehouse_id"`
+	ToWarehouseID   int        `json:"to_warehouse_id"`
+	ProductCode     string     `json:"product_code"`
+	Count           int        `json:"count"`
+	Status          string     `json:"status"`
+	CreatedBy       string     `json:"created_by"`
+	ReceivedBy      string     `json:"received_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ReceivedAt      *time.Time `json:"received_at"`
+	Lots            []LotCount `json:"lots"`
+	SerialNumbers   []string   `json:"serial_numbers"`
 }
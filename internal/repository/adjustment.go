@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"github.com/lib/pq"
+	"time"
+)
+
+var ErrAdjustmentNotPending = errors.New("adjustment is not pending")
+
+// CreateAdjustment records the adjustment with its status. An applied adjustment changes the
+// stock in the same transaction, so if it cannot be applied nothing is recorded; a pending one
+// waits for ReviewAdjustment.
+func (r *warehouseRepository) CreateAdjustment(adjustment *model.Adjustment) error {
+	r.Logger.Info("start repository CreateAdjustment")
+
+	if adjustment.Delta == 0 {
+		return ErrInvalidCount
+	}
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		serialized, err := adjustedProduct(tx, adjustment)
+		if err != nil {
+			return err
+		}
+
+		if err = insertAdjustment(tx, adjustment); err != nil {
+			return err
+		}
+
+		if adjustment.Status != model.AdjustmentApplied {
+			return nil
+		}
+		return applyAdjustment(tx, adjustment, serialized)
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// ReviewAdjustment applies or rejects a pending adjustment, as status tells. If an approved
+// adjustment cannot be applied, for instance because the stock it writes off has been reserved
+// in the meantime, it stays pending.
+func (r *warehouseRepository) ReviewAdjustment(adjustmentID int, status string, userID string) (*model.Adjustment, error) {
+	r.Logger.Info("start repository ReviewAdjustment")
+
+	var adjustment *model.Adjustment
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + adjustmentColumns + ` FROM stock_adjustment WHERE id = $1 FOR UPDATE;`
+		var err error
+		adjustment, err = scanAdjustment(tx.QueryRow(query, adjustmentID))
+		if err != nil {
+			return err
+		}
+
+		if adjustment.Status != model.AdjustmentPending {
+			return ErrAdjustmentNotPending
+		}
+
+		now := time.Now()
+		adjustment.Status = status
+		adjustment.ReviewedBy = userID
+		adjustment.ReviewedAt = &now
+		if status == model.AdjustmentApplied {
+			serialized, err := adjustedProduct(tx, adjustment)
+			if err != nil {
+				return err
+			}
+			if err = applyAdjustment(tx, adjustment, serialized); err != nil {
+				return err
+			}
+		}
+
+		query = `UPDATE stock_adjustment SET status = $1, reviewed_by = $2, reviewed_at = $3 WHERE id = $4;`
+		_, err = tx.Exec(query, adjustment.Status, nullString(userID), now, adjustment.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// ListAdjustments returns the adjustments matching filter ordered by ID.
+func (r *warehouseRepository) ListAdjustments(filter *model.AdjustmentFilter) ([]model.Adjustment, error) {
+	r.Logger.Info("start repository ListAdjustments")
+
+	query := `SELECT ` + adjustmentColumns + ` FROM stock_adjustment
+		WHERE id > $1
+		  AND ($2 = 0 OR warehouse_id = $2)
+		  AND ($3 = '' OR product_code = $3)
+		  AND ($4 = '' OR status = $4)
+		ORDER BY id
+		LIMIT $5;`
+	rows, err := r.DB.Query(query, filter.Cursor, filter.WarehouseID, filter.ProductCode, filter.Status, filter.Limit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := make([]model.Adjustment, 0)
+	for rows.Next() {
+		adjustment, err := scanAdjustment(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		adjustments = append(adjustments, *adjustment)
+	}
+	return adjustments, rows.Err()
+}
+
+// insertAdjustment records the adjustment with its status, without changing the stock.
+func insertAdjustment(tx *sql.Tx, adjustment *model.Adjustment) error {
+	query := `INSERT INTO stock_adjustment (warehouse_id, product_code, delta, reason, note, serial_numbers, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at;`
+	return tx.QueryRow(query, adjustment.WarehouseID, adjustment.ProductCode, adjustment.Delta, adjustment.Reason,
+		adjustment.Note, pq.Array(adjustment.SerialNumbers), adjustment.Status, nullString(adjustment.CreatedBy)).
+		Scan(&adjustment.ID, &adjustment.CreatedAt)
+}
+
+// adjustedProduct locks the product of the adjustment against catalog changes and reports
+// whether it is serialized. It fails unless the adjustment lists a serial number for every
+// unit of a serialized product, or none for a product that is not serialized.
+func adjustedProduct(tx *sql.Tx, adjustment *model.Adjustment) (bool, error) {
+	query := `SELECT serialized FROM product WHERE unique_code = $1 AND deleted_at IS NULL FOR SHARE;`
+	serialized := false
+	if err := tx.QueryRow(query, adjustment.ProductCode).Scan(&serialized); err != nil {
+		return false, err
+	}
+	return serialized, checkSerials(adjustmentReceipt(adjustment), serialized)
+}
+
+// applyAdjustment adds the delta of the adjustment to total_count and left_count of its product
+// in the warehouse and records the movement. A write-off can only take free stock, otherwise it
+// fails with ErrNotEnoughLeft, also when the product is not stocked in the warehouse at all. It
+// is taken from the lots earliest expiry first and the listed units are written off. Added
+// stock is not put in a lot, its units are registered as available, and it is first offered
+// to waiting backorders. It fails with ErrCapacityExceeded if the warehouse cannot hold the
+// added stock.
+func applyAdjustment(tx *sql.Tx, adjustment *model.Adjustment, serialized bool) error {
+	if adjustment.Delta < 0 {
+		query := `SELECT left_count FROM warehouse_product WHERE warehouse_id = $1 AND product_code = $2 FOR UPDATE;`
+		left := 0
+		err := tx.QueryRow(query, adjustment.WarehouseID, adjustment.ProductCode).Scan(&left)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if left < -adjustment.Delta {
+			return ErrNotEnoughLeft
+		}
+	}
+
+	_, err := addStock(tx, adjustment.WarehouseID, adjustment.ProductCode, adjustment.Delta)
+	if err != nil {
+		return err
+	}
+	if adjustment.Delta < 0 {
+		adjustment.Lots, err = takeLots(tx, adjustment.WarehouseID, adjustment.ProductCode, -adjustment.Delta, true, true)
+		if err != nil {
+			return err
+		}
+		if serialized {
+			err = writeOffSerials(tx, adjustment)
+		}
+	} else {
+		if err = checkCapacity(tx, adjustment.WarehouseID); err != nil {
+			return err
+		}
+		if serialized {
+			err = registerSerials(tx, adjustmentReceipt(adjustment))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = insertMovement(tx, &model.StockMovement{
+		WarehouseID: adjustment.WarehouseID,
+		ProductCode: adjustment.ProductCode,
+		Delta:       adjustment.Delta,
+		TotalDelta:  adjustment.Delta,
+		Reason:      model.MovementAdjust,
+		UserID:      adjustment.AppliedBy(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if adjustment.Delta > 0 {
+		_, err = allocateBackorders(tx, adjustment.WarehouseID, adjustment.ProductCode)
+	}
+	return err
+}
+
+// adjustmentReceipt describes the units added or written off by the adjustment as a receipt,
+// for the checks and registration shared with received stock.
+func adjustmentReceipt(adjustment *model.Adjustment) *model.Receipt {
+	count := adjustment.Delta
+	if count < 0 {
+		count = -count
+	}
+	return &model.Receipt{
+		WarehouseID:   adjustment.WarehouseID,
+		ProductCode:   adjustment.ProductCode,
+		Count:         count,
+		SerialNumbers: adjustment.SerialNumbers,
+		UserID:        adjustment.AppliedBy(),
+	}
+}
+
+const adjustmentColumns = `id, warehouse_id, product_code, delta, reason, note, serial_numbers, status, created_by,
+	reviewed_by, created_at, reviewed_at`
+
+// scanAdjustment scans a row selected with adjustmentColumns.
+func scanAdjustment(row scanner) (*model.Adjustment, error) {
+	adjustment := &model.Adjustment{}
+	createdBy, reviewedBy, reviewedAt := sql.NullString{}, sql.NullString{}, sql.NullTime{}
+	err := row.Scan(&adjustment.ID, &adjustment.WarehouseID, &adjustment.ProductCode, &adjustment.Delta,
+		&adjustment.Reason, &adjustment.Note, pq.Array(&adjustment.SerialNumbers), &adjustment.Status, &createdBy,
+		&reviewedBy, &adjustment.CreatedAt, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.CreatedBy = createdBy.String
+	adjustment.ReviewedBy = reviewedBy.String
+	if reviewedAt.Valid {
+		adjustment.ReviewedAt = &reviewedAt.Time
+	}
+	return adjustment, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"example1/internal/model"
+	"github.com/lib/pq"
+)
+
+// Availability returns the stock of the products with the given codes in every available
+// warehouse, ordered by product code and then by free stock, largest first.
+func (r *warehouseRepository) Availability(productCodes []string) ([]model.WarehouseProduct, error) {
+	r.Logger.Info("start repository Availability")
+
+	query := `SELECT wp.id, wp.warehouse_id, wp.product_code, wp.total_count, wp.left_count, wp.reorder_threshold
+		FROM warehouse_product wp
+		JOIN warehouse w ON w.id = wp.warehouse_id
+		WHERE wp.product_code = ANY ($1) AND w.available
+		ORDER BY wp.product_code, wp.left_count DESC, wp.warehouse_id;`
+	rows, err := r.DB.Query(query, pq.Array(productCodes))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.WarehouseProduct, 0)
+	for rows.Next() {
+		item, err := scanWarehouseProduct(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"time"
+)
+
+var ErrBackorderNotWaiting = errors.New("backorder is not waiting")
+
+// Backorder reserves the free stock of the product in the warehouse that the waiting backorders
+// are not queued for and queues the shortfall behind them. The count of the reservation is set
+// to the count actually reserved, and its ID is zero if no stock was free. If enough stock was
+// freed since the reservation failed, the whole line is reserved and backorder is not queued,
+// so its ID stays zero.
+func (r *productRepository) Backorder(reservation *model.Reservation, backorder *model.Backorder) error {
+	r.Logger.Info("start repository Backorder")
+
+	if reservation.Count <= 0 {
+		return ErrInvalidCount
+	}
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT left_count FROM warehouse_product WHERE product_code = $1 AND warehouse_id = $2 FOR UPDATE;`
+		left := 0
+		err := tx.QueryRow(query, reservation.ProductCode, reservation.WarehouseID).Scan(&left)
+		if err != nil {
+			return err
+		}
+
+		queued, err := queuedCount(tx, reservation.WarehouseID, reservation.ProductCode)
+		if err != nil {
+			return err
+		}
+		free := freeCount(left, queued)
+
+		backorder.Count = reservation.Count - free
+		reservation.ID = 0
+		if free > 0 {
+			reservation.Count = min(reservation.Count, free)
+			if err = reserve(tx, reservation, false); err != nil {
+				return err
+			}
+		}
+		if backorder.Count <= 0 {
+			return nil
+		}
+
+		backorder.Status = model.BackorderWaiting
+		query = `INSERT INTO backorder (warehouse_id, product_code, count, ttl, status, user_id)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at;`
+		return tx.QueryRow(query, backorder.WarehouseID, backorder.ProductCode, backorder.Count, backorder.TTL,
+			backorder.Status, nullString(backorder.UserID)).Scan(&backorder.ID, &backorder.CreatedAt)
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// CancelBackorder cancels a waiting backorder. Backorders queued behind it may then be
+// fulfilled from the stock it was waiting for. The stock row is locked before the backorder,
+// in the same order as Backorder and ReceiveProduct, so that they cannot deadlock.
+func (r *productRepository) CancelBackorder(backorderID int) (*model.Backorder, error) {
+	r.Logger.Info("start repository CancelBackorder")
+
+	var backorder *model.Backorder
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT warehouse_id, product_code FROM backorder WHERE id = $1;`
+		warehouseID, productCode := 0, ""
+		err := tx.QueryRow(query, backorderID).Scan(&warehouseID, &productCode)
+		if err != nil {
+			return err
+		}
+
+		query = `SELECT id FROM warehouse_product WHERE product_code = $1 AND warehouse_id = $2 FOR UPDATE;`
+		id := 0
+		err = tx.QueryRow(query, productCode, warehouseID).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		query = `SELECT ` + backorderColumns + ` FROM backorder WHERE id = $1 FOR UPDATE;`
+		backorder, err = scanBackorder(tx.QueryRow(query, backorderID))
+		if err != nil {
+			return err
+		}
+
+		if backorder.Status != model.BackorderWaiting {
+			return ErrBackorderNotWaiting
+		}
+
+		backorder.Status = model.BackorderCancelled
+		query = `UPDATE backorder SET status = $1 WHERE id = $2;`
+		_, err = tx.Exec(query, backorder.Status, backorder.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = allocateBackorders(tx, backorder.WarehouseID, backorder.ProductCode)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return backorder, err
+	}
+	return backorder, nil
+}
+
+func (r *productRepository) GetBackorder(backorderID int) (*model.Backorder, error) {
+	r.Logger.Info("start repository GetBackorder")
+
+	query := `SELECT ` + backorderColumns + ` FROM backorder WHERE id = $1;`
+	backorder, err := scanBackorder(r.DB.QueryRow(query, backorderID))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return backorder, nil
+}
+
+// ListBackorders returns the backorders matching filter ordered by ID, which is also the order
+// in which waiting backorders are fulfilled.
+func (r *productRepository) ListBackorders(filter *model.BackorderFilter) ([]model.Backorder, error) {
+	r.Logger.Info("start repository ListBackorders")
+
+	query := `SELECT ` + backorderColumns + ` FROM backorder
+		WHERE id > $1
+		  AND ($2 = 0 OR warehouse_id = $2)
+		  AND ($3 = '' OR product_code = $3)
+		  AND ($4 = '' OR user_id = $4)
+		  AND ($5 = '' OR status = $5)
+		ORDER BY id
+		LIMIT $6;`
+	rows, err := r.DB.Query(query, filter.Cursor, filter.WarehouseID, filter.ProductCode, filter.UserID,
+		filter.Status, filter.Limit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	backorders := make([]model.Backorder, 0)
+	for rows.Next() {
+		backorder, err := scanBackorder(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		backorders = append(backorders, *backorder)
+	}
+	return backorders, rows.Err()
+}
+
+// allocateBackorders reserves free stock of the product in the warehouse for the waiting
+// backorders in the order they were queued and returns the count reserved. Allocation stops at
+// the first backorder that cannot be met in full, so a later, smaller backorder never
+// overtakes it; the stock left free stays held for the queue, see reserve. It must be called
+// after every change that adds to left_count.
+func allocateBackorders(tx *sql.Tx, warehouseID int, productCode string) (int, error) {
+	query := `SELECT ` + backorderColumns + ` FROM backorder
+		WHERE warehouse_id = $1 AND product_code = $2 AND status = $3
+		ORDER BY id FOR UPDATE;`
+	rows, err := tx.Query(query, warehouseID, productCode, model.BackorderWaiting)
+	if err != nil {
+		return 0, err
+	}
+	var waiting []*model.Backorder
+	for rows.Next() {
+		backorder, err := scanBackorder(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		waiting = append(waiting, backorder)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	allocated := 0
+	for _, backorder := range waiting {
+		reservation := &model.Reservation{
+			WarehouseID: backorder.WarehouseID,
+			ProductCode: backorder.ProductCode,
+			Count:       backorder.Count,
+			UserID:      backorder.UserID,
+		}
+		if backorder.TTL > 0 {
+			expiresAt := time.Now().Add(time.Duration(backorder.TTL) * time.Minute)
+			reservation.ExpiresAt = &expiresAt
+		}
+
+		err = reserve(tx, reservation, true)
+		if errors.Is(err, ErrNotEnoughLeft) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		query = `UPDATE backorder SET status = $1, reservation_id = $2 WHERE id = $3;`
+		_, err = tx.Exec(query, model.BackorderFulfilled, reservation.ID, backorder.ID)
+		if err != nil {
+			return 0, err
+		}
+		allocated += backorder.Count
+	}
+	return allocated, nil
+}
+
+// queuedCount returns the count the waiting backorders for the product in the warehouse are
+// queued for. The warehouse_product row must be locked, as backorders are queued and allocated
+// under that lock.
+func queuedCount(tx *sql.Tx, warehouseID int, productCode string) (int, error) {
+	query := `SELECT COALESCE(SUM(count), 0) FROM backorder
+		WHERE warehouse_id = $1 AND product_code = $2 AND status = $3;`
+	queued := 0
+	err := tx.QueryRow(query, warehouseID, productCode, model.BackorderWaiting).Scan(&queued)
+	return queued, err
+}
+
+// freeCount returns how much of left a new reservation may take when queued is held for the
+// waiting backorders.
+func freeCount(left int, queued int) int {
+	return max(left-queued, 0)
+}
+
+const backorderColumns = `id, warehouse_id, product_code, count, ttl, status, user_id, reservation_id, created_at`
+
+// scanBackorder scans a row selected with backorderColumns.
+func scanBackorder(row scanner) (*model.Backorder, error) {
+	backorder := &model.Backorder{}
+	userID := sql.NullString{}
+	reservationID := sql.NullInt64{}
+	err := row.Scan(&backorder.ID, &backorder.WarehouseID, &backorder.ProductCode, &backorder.Count, &backorder.TTL,
+		&backorder.Status, &userID, &reservationID, &backorder.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	backorder.UserID = userID.String
+	backorder.ReservationID = int(reservationID.Int64)
+	return backorder, nil
+}
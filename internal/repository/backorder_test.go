@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestFreeCount(t *testing.T) {
+	testTable := []struct {
+		name     string
+		left     int
+		queued   int
+		expected int
+	}{
+		{
+			name:     "No waiting backorders",
+			left:     50,
+			expected: 50,
+		},
+		{
+			name:     "Waiting backorder holds the stock",
+			left:     50,
+			queued:   100,
+			expected: 0,
+		},
+		{
+			name:     "Stock beyond the queue",
+			left:     150,
+			queued:   100,
+			expected: 50,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, freeCount(test.left, test.queued))
+		})
+	}
+}
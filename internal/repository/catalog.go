@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"example1/pkg/logger"
+	"time"
+)
+
+var ErrProductDeleted = errors.New("product is deleted")
+var ErrProductInStock = errors.New("product is in stock")
+var ErrProductReserved = errors.New("product has live reservations or backorders")
+
+type catalogRepository struct {
+	DB     *sql.DB
+	Logger logger.Logger
+}
+
+func NewCatalogRepository(db *sql.DB) CatalogRepository {
+	return &catalogRepository{db, logger.Get()}
+}
+
+//go:generate mockgen -source=catalog.go -destination=mocks/mock_catalog.go
+
+type CatalogRepository interface {
+	CreateProduct(product *model.Product) error
+	UpdateProduct(product *model.Product) error
+	GetProduct(uniqueCode string) (*model.Product, error)
+	ListProducts(filter *model.ProductFilter) ([]model.Product, error)
+	DeleteProduct(uniqueCode string) (*model.Product, error)
+}
+
+// CreateProduct adds the product to the catalog. It returns sql.ErrNoRows if a product with
+// the same unique code, deleted or not, already exists.
+func (r *catalogRepository) CreateProduct(product *model.Product) error {
+	r.Logger.Info("start repository CreateProduct")
+
+	query := `INSERT INTO product (name, size, unique_code, serialized) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (unique_code) DO NOTHING RETURNING id;`
+	err := r.DB.QueryRow(query, product.Name, product.Size, product.UniqueCode, product.Serialized).Scan(&product.ID)
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// UpdateProduct changes the name, size and serialized flag of a product that is not deleted.
+// The unique code identifies the product and cannot be changed. The serialized flag cannot be
+// changed while the product is stocked in any warehouse or in transit between them.
+func (r *catalogRepository) UpdateProduct(product *model.Product) error {
+	r.Logger.Info("start repository UpdateProduct")
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT id, serialized FROM product WHERE unique_code = $1 AND deleted_at IS NULL FOR UPDATE;`
+		serialized := false
+		err := tx.QueryRow(query, product.UniqueCode).Scan(&product.ID, &serialized)
+		if err != nil {
+			return err
+		}
+
+		if serialized != product.Serialized {
+			query = `SELECT EXISTS (SELECT 1 FROM warehouse_product WHERE product_code = $1 AND total_count > 0)
+				OR EXISTS (SELECT 1 FROM stock_transfer WHERE product_code = $1 AND status = $2);`
+			stocked := false
+			err = tx.QueryRow(query, product.UniqueCode, model.TransferInTransit).Scan(&stocked)
+			if err != nil {
+				return err
+			}
+			if stocked {
+				return ErrProductInStock
+			}
+		}
+
+		query = `UPDATE product SET name = $1, size = $2, serialized = $3 WHERE id = $4;`
+		_, err = tx.Exec(query, product.Name, product.Size, product.Serialized, product.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetProduct returns the product with the given unique code, including a deleted one.
+func (r *catalogRepository) GetProduct(uniqueCode string) (*model.Product, error) {
+	r.Logger.Info("start repository GetProduct")
+
+	query := `SELECT ` + productColumns + ` FROM product WHERE unique_code = $1;`
+	product, err := scanProduct(r.DB.QueryRow(query, uniqueCode))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return product, nil
+}
+
+// ListProducts returns the products matching filter ordered by ID.
+func (r *catalogRepository) ListProducts(filter *model.ProductFilter) ([]model.Product, error) {
+	r.Logger.Info("start repository ListProducts")
+
+	query := `SELECT ` + productColumns + ` FROM product
+		WHERE id > $1
+		  AND ($2 = '' OR name ILIKE '%' || $2 || '%')
+		  AND ($3 OR deleted_at IS NULL)
+		ORDER BY id
+		LIMIT $4;`
+	rows, err := r.DB.Query(query, filter.Cursor, likeEscaper.Replace(filter.Name), filter.WithDeleted, filter.Limit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0)
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		products = append(products, *product)
+	}
+	return products, rows.Err()
+}
+
+// DeleteProduct marks the product as deleted. A product that is still stocked in any warehouse
+// or in transit between them, or has live reservations or waiting backorders is not deleted.
+// Deleted products keep their unique code, so it cannot be reused.
+func (r *catalogRepository) DeleteProduct(uniqueCode string) (*model.Product, error) {
+	r.Logger.Info("start repository DeleteProduct")
+
+	var product *model.Product
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + productColumns + ` FROM product WHERE unique_code = $1 FOR UPDATE;`
+		var err error
+		product, err = scanProduct(tx.QueryRow(query, uniqueCode))
+		if err != nil {
+			return err
+		}
+		if product.DeletedAt != nil {
+			return ErrProductDeleted
+		}
+
+		// Lock the stock rows so that nothing can be received or reserved while deleting.
+		query = `SELECT total_count FROM warehouse_product WHERE product_code = $1 FOR UPDATE;`
+		rows, err := tx.Query(query, uniqueCode)
+		if err != nil {
+			return err
+		}
+		total := 0
+		for rows.Next() {
+			count := 0
+			if err = rows.Scan(&count); err != nil {
+				rows.Close()
+				return err
+			}
+			total += count
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		// Stock in transit has left total_count of the source, but is received back into a warehouse.
+		query = `SELECT EXISTS (SELECT 1 FROM stock_transfer WHERE product_code = $1 AND status = $2);`
+		inTransit := false
+		if err = tx.QueryRow(query, uniqueCode, model.TransferInTransit).Scan(&inTransit); err != nil {
+			return err
+		}
+		if total > 0 || inTransit {
+			return ErrProductInStock
+		}
+
+		query = `SELECT EXISTS (SELECT 1 FROM reservation WHERE product_code = $1 AND status IN ($2, $3))
+			OR EXISTS (SELECT 1 FROM backorder WHERE product_code = $1 AND status = $4);`
+		live := false
+		err = tx.QueryRow(query, uniqueCode, model.StatusReserved, model.StatusConfirmed, model.BackorderWaiting).
+			Scan(&live)
+		if err != nil {
+			return err
+		}
+		if live {
+			return ErrProductReserved
+		}
+
+		now := time.Now()
+		product.DeletedAt = &now
+		query = `UPDATE product SET deleted_at = $1 WHERE id = $2;`
+		_, err = tx.Exec(query, now, product.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return product, nil
+}
+
+const productColumns = `id, COALESCE(name, ''), COALESCE(size, 0), unique_code, serialized, deleted_at`
+
+// scanProduct scans a row selected with productColumns.
+func scanProduct(row scanner) (*model.Product, error) {
+	product := &model.Product{}
+	err := row.Scan(&product.ID, &product.Name, &product.Size, &product.UniqueCode, &product.Serialized,
+		&product.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"fmt"
+	"github.com/lib/pq"
+	"time"
+)
+
+var ErrCycleCountOpen = errors.New("cycle count is already open in the warehouse")
+var ErrCycleCountNotOpen = errors.New("cycle count is not open")
+var ErrCycleCountClosed = errors.New("cycle count is closed")
+var ErrNotInCycleCount = errors.New("product is not included in the cycle count")
+var ErrSerializedCount = errors.New("serialized products cannot be cycle counted")
+var ErrCountSettled = errors.New("count of the product is already settled")
+var ErrCountNotSettled = errors.New("cycle count has unsettled lines")
+
+// OpenCycleCount opens the count in its warehouse. It fails with ErrCycleCountOpen if the
+// warehouse has a count that is not closed yet, and with sql.ErrNoRows if one of the products
+// the count is limited to is not in the catalog.
+func (r *warehouseRepository) OpenCycleCount(count *model.CycleCount) error {
+	r.Logger.Info("start repository OpenCycleCount")
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		if len(count.ProductCodes) != 0 {
+			query := `SELECT COUNT(*) FROM product WHERE unique_code = ANY($1) AND deleted_at IS NULL;`
+			found := 0
+			if err := tx.QueryRow(query, pq.Array(count.ProductCodes)).Scan(&found); err != nil {
+				return err
+			}
+			if found != len(count.ProductCodes) {
+				return sql.ErrNoRows
+			}
+		}
+
+		count.Status = model.CycleCountOpen
+		query := `INSERT INTO cycle_count (warehouse_id, product_codes, status, created_by) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (warehouse_id) WHERE status <> 'closed' DO NOTHING RETURNING id, created_at;`
+		err := tx.QueryRow(query, count.WarehouseID, pq.Array(count.ProductCodes), count.Status,
+			nullString(count.CreatedBy)).Scan(&count.ID, &count.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCycleCountOpen
+		}
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetCycleCount returns the count with its lines ordered by product code.
+func (r *warehouseRepository) GetCycleCount(countID int) (*model.CycleCount, error) {
+	r.Logger.Info("start repository GetCycleCount")
+
+	query := `SELECT ` + cycleCountColumns + ` FROM cycle_count WHERE id = $1;`
+	count, err := scanCycleCount(r.DB.QueryRow(query, countID))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	count.Lines, err = cycleCountLines(r.DB, countID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return count, nil
+}
+
+// CountProduct records the counted quantity of line.ProductCode in an open count together with
+// total_count of the product in the warehouse at that moment. A product can be counted again
+// until the count is closed, the last count wins.
+func (r *warehouseRepository) CountProduct(line *model.CycleCountLine) error {
+	r.Logger.Info("start repository CountProduct")
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + cycleCountColumns + ` FROM cycle_count WHERE id = $1 FOR SHARE;`
+		count, err := scanCycleCount(tx.QueryRow(query, line.CycleCountID))
+		if err != nil {
+			return err
+		}
+		if count.Status != model.CycleCountOpen {
+			return ErrCycleCountNotOpen
+		}
+		if !count.Covers(line.ProductCode) {
+			return ErrNotInCycleCount
+		}
+
+		query = `SELECT serialized FROM product WHERE unique_code = $1 AND deleted_at IS NULL;`
+		serialized := false
+		if err = tx.QueryRow(query, line.ProductCode).Scan(&serialized); err != nil {
+			return err
+		}
+		if serialized {
+			return ErrSerializedCount
+		}
+
+		query = `SELECT total_count FROM warehouse_product WHERE warehouse_id = $1 AND product_code = $2;`
+		line.Expected = 0
+		err = tx.QueryRow(query, count.WarehouseID, line.ProductCode).Scan(&line.Expected)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		line.Status = model.CountLineCounted
+		query = `INSERT INTO cycle_count_line (cycle_count_id, product_code, counted, expected, status, counted_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (cycle_count_id, product_code) DO UPDATE
+			SET counted = EXCLUDED.counted, expected = EXCLUDED.expected, counted_by = EXCLUDED.counted_by,
+			    counted_at = now()
+			WHERE cycle_count_line.status = EXCLUDED.status
+			RETURNING ` + cycleCountLineColumns + `;`
+		counted, err := scanCycleCountLine(tx.QueryRow(query, line.CycleCountID, line.ProductCode, line.Counted,
+			line.Expected, line.Status, nullString(line.CountedBy)))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCountSettled
+		}
+		if err != nil {
+			return err
+		}
+		*line = *counted
+		return nil
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// FreezeCycleCount stops counting in an open count so that it can be settled, and returns the
+// count with its lines. A count that is already closing is returned as it is.
+func (r *warehouseRepository) FreezeCycleCount(countID int) (*model.CycleCount, error) {
+	r.Logger.Info("start repository FreezeCycleCount")
+
+	var count *model.CycleCount
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + cycleCountColumns + ` FROM cycle_count WHERE id = $1 FOR UPDATE;`
+		var err error
+		count, err = scanCycleCount(tx.QueryRow(query, countID))
+		if err != nil {
+			return err
+		}
+
+		switch count.Status {
+		case model.CycleCountClosed:
+			return ErrCycleCountClosed
+		case model.CycleCountOpen:
+			count.Status = model.CycleCountClosing
+			query = `UPDATE cycle_count SET status = $1 WHERE id = $2;`
+			if _, err = tx.Exec(query, count.Status, count.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+
+	count.Lines, err = cycleCountLines(r.DB, countID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return count, nil
+}
+
+// SettleCountLine settles the counted line of the product in a closing count. Unless approved
+// is set the line is skipped. Otherwise its variance is applied as an adjustment with the
+// cycle-count reason made by userID; a shortage is written off only as far as the free stock
+// goes, the rest being held by live reservations. The line is returned with the adjustment, if
+// one was made; a line that is already settled is returned unchanged.
+func (r *warehouseRepository) SettleCountLine(countID int, productCode string, approved bool,
+	userID string) (*model.CycleCountLine, *model.Adjustment, error) {
+	r.Logger.Info("start repository SettleCountLine")
+
+	var line *model.CycleCountLine
+	var adjustment *model.Adjustment
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + cycleCountColumns + ` FROM cycle_count WHERE id = $1 FOR SHARE;`
+		count, err := scanCycleCount(tx.QueryRow(query, countID))
+		if err != nil {
+			return err
+		}
+		if count.Status != model.CycleCountClosing {
+			return ErrCycleCountClosed
+		}
+
+		query = `SELECT ` + cycleCountLineColumns + ` FROM cycle_count_line
+			WHERE cycle_count_id = $1 AND product_code = $2 FOR UPDATE;`
+		line, err = scanCycleCountLine(tx.QueryRow(query, countID, productCode))
+		if err != nil {
+			return err
+		}
+		if line.Status != model.CountLineCounted {
+			return nil
+		}
+
+		switch {
+		case !approved:
+			line.Status = model.CountLineSkipped
+		case line.Variance() == 0:
+			line.Status = model.CountLineMatched
+		default:
+			adjustment, err = settleVariance(tx, count, line, userID)
+			if err != nil {
+				return err
+			}
+			line.Status = model.CountLineAdjusted
+		}
+
+		query = `UPDATE cycle_count_line SET status = $1, adjusted = $2, adjustment_id = $3 WHERE id = $4;`
+		_, err = tx.Exec(query, line.Status, line.Adjusted, nullInt(line.AdjustmentID), line.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, nil, err
+	}
+	return line, adjustment, nil
+}
+
+// CloseCycleCount closes a closing count once all of its lines are settled, otherwise it fails
+// with ErrCountNotSettled.
+func (r *warehouseRepository) CloseCycleCount(countID int, userID string) (*model.CycleCount, error) {
+	r.Logger.Info("start repository CloseCycleCount")
+
+	var count *model.CycleCount
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT ` + cycleCountColumns + ` FROM cycle_count WHERE id = $1 FOR UPDATE;`
+		var err error
+		count, err = scanCycleCount(tx.QueryRow(query, countID))
+		if err != nil {
+			return err
+		}
+		if count.Status == model.CycleCountClosed {
+			return ErrCycleCountClosed
+		}
+
+		query = `SELECT EXISTS (SELECT 1 FROM cycle_count_line WHERE cycle_count_id = $1 AND status = $2);`
+		unsettled := false
+		if err = tx.QueryRow(query, countID, model.CountLineCounted).Scan(&unsettled); err != nil {
+			return err
+		}
+		if count.Status != model.CycleCountClosing || unsettled {
+			return ErrCountNotSettled
+		}
+
+		now := time.Now()
+		count.Status = model.CycleCountClosed
+		count.ClosedBy = userID
+		count.ClosedAt = &now
+		query = `UPDATE cycle_count SET status = $1, closed_by = $2, closed_at = $3 WHERE id = $4;`
+		_, err = tx.Exec(query, count.Status, nullString(userID), now, count.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+
+	count.Lines, err = cycleCountLines(r.DB, countID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return count, nil
+}
+
+// settleVariance applies the variance of the line with applyAdjustment, limiting a shortage to
+// the free stock of the product, and records the applied part in the line. No adjustment is
+// made if all of the shortage is reserved.
+func settleVariance(tx *sql.Tx, count *model.CycleCount, line *model.CycleCountLine,
+	userID string) (*model.Adjustment, error) {
+	adjustment := &model.Adjustment{
+		WarehouseID: count.WarehouseID,
+		ProductCode: line.ProductCode,
+		Delta:       line.Variance(),
+		Reason:      model.AdjustmentCycleCount,
+		Note:        fmt.Sprintf("cycle count %d", count.ID),
+		Status:      model.AdjustmentApplied,
+		CreatedBy:   userID,
+	}
+	serialized, err := adjustedProduct(tx, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	if adjustment.Delta < 0 {
+		query := `SELECT left_count FROM warehouse_product WHERE warehouse_id = $1 AND product_code = $2 FOR UPDATE;`
+		left := 0
+		err = tx.QueryRow(query, adjustment.WarehouseID, adjustment.ProductCode).Scan(&left)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		adjustment.Delta = max(adjustment.Delta, -left)
+	}
+	line.Adjusted = adjustment.Delta
+	if adjustment.Delta == 0 {
+		return nil, nil
+	}
+
+	if err = insertAdjustment(tx, adjustment); err != nil {
+		return nil, err
+	}
+	if err = applyAdjustment(tx, adjustment, serialized); err != nil {
+		return nil, err
+	}
+	line.AdjustmentID = adjustment.ID
+	return adjustment, nil
+}
+
+// cycleCountLines returns the lines of the count ordered by product code.
+func cycleCountLines(db *sql.DB, countID int) ([]model.CycleCountLine, error) {
+	query := `SELECT ` + cycleCountLineColumns + ` FROM cycle_count_line WHERE cycle_count_id = $1 ORDER BY product_code;`
+	rows, err := db.Query(query, countID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]model.CycleCountLine, 0)
+	for rows.Next() {
+		line, err := scanCycleCountLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, *line)
+	}
+	return lines, rows.Err()
+}
+
+const cycleCountColumns = `id, warehouse_id, product_codes, status, created_by, closed_by, created_at, closed_at`
+
+// scanCycleCount scans a row selected with cycleCountColumns.
+func scanCycleCount(row scanner) (*model.CycleCount, error) {
+	count := &model.CycleCount{}
+	createdBy, closedBy, closedAt := sql.NullString{}, sql.NullString{}, sql.NullTime{}
+	err := row.Scan(&count.ID, &count.WarehouseID, pq.Array(&count.ProductCodes), &count.Status, &createdBy,
+		&closedBy, &count.CreatedAt, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+	count.CreatedBy = createdBy.String
+	count.ClosedBy = closedBy.String
+	if closedAt.Valid {
+		count.ClosedAt = &closedAt.Time
+	}
+	return count, nil
+}
+
+const cycleCountLineColumns = `id, cycle_count_id, product_code, counted, expected, status, adjusted, adjustment_id,
+	counted_by, counted_at`
+
+// scanCycleCountLine scans a row selected with cycleCountLineColumns.
+func scanCycleCountLine(row scanner) (*model.CycleCountLine, error) {
+	line := &model.CycleCountLine{}
+	adjustmentID, countedBy := sql.NullInt64{}, sql.NullString{}
+	err := row.Scan(&line.ID, &line.CycleCountID, &line.ProductCode, &line.Counted, &line.Expected, &line.Status,
+		&line.Adjusted, &adjustmentID, &countedBy, &line.CountedAt)
+	if err != nil {
+		return nil, err
+	}
+	line.AdjustmentID = int(adjustmentID.Int64)
+	line.CountedBy = countedBy.String
+	return line, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	log "example1/pkg/logger"
+	"time"
+)
+
+type idempotencyRepository struct {
+	DB     *sql.DB
+	Logger log.Logger
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db, log.Get()}
+}
+
+type IdempotencyRepository interface {
+	Claim(req *model.IdempotentRequest, since time.Time) (*model.IdempotentRequest, error)
+	SaveResponse(req *model.IdempotentRequest) error
+	Release(userID, key string) error
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+// Claim records req as the first request with its key unless a request with the same user and
+// key was made since the given time. It returns nil if req was recorded and the earlier request
+// otherwise. Older requests with the key are forgotten.
+func (r *idempotencyRepository) Claim(req *model.IdempotentRequest, since time.Time) (*model.IdempotentRequest, error) {
+	r.Logger.Info("start repository Claim")
+
+	var existing *model.IdempotentRequest
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `DELETE FROM idempotency_key WHERE user_id = $1 AND key = $2 AND created_at < $3;`
+		_, err := tx.Exec(query, req.UserID, req.Key, since)
+		if err != nil {
+			return err
+		}
+
+		query = `INSERT INTO idempotency_key (user_id, key, request_hash) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING RETURNING created_at;`
+		err = tx.QueryRow(query, req.UserID, req.Key, req.RequestHash).Scan(&req.CreatedAt)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		existing = &model.IdempotentRequest{UserID: req.UserID, Key: req.Key}
+		statusCode := sql.NullInt64{}
+		query = `SELECT request_hash, status_code, response, created_at FROM idempotency_key WHERE user_id = $1 AND key = $2;`
+		err = tx.QueryRow(query, req.UserID, req.Key).
+			Scan(&existing.RequestHash, &statusCode, &existing.Response, &existing.CreatedAt)
+		existing.StatusCode = int(statusCode.Int64)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return existing, nil
+}
+
+// SaveResponse stores the response to a claimed request.
+func (r *idempotencyRepository) SaveResponse(req *model.IdempotentRequest) error {
+	r.Logger.Info("start repository SaveResponse")
+
+	query := `UPDATE idempotency_key SET status_code = $1, response = $2 WHERE user_id = $3 AND key = $4;`
+	_, err := r.DB.Exec(query, req.StatusCode, req.Response, req.UserID, req.Key)
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Release forgets a claimed request so that it can be retried with the same key.
+func (r *idempotencyRepository) Release(userID, key string) error {
+	r.Logger.Info("start repository Release")
+
+	query := `DELETE FROM idempotency_key WHERE user_id = $1 AND key = $2;`
+	_, err := r.DB.Exec(query, userID, key)
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// DeleteBefore forgets every request made before the given time and returns how many there were.
+func (r *idempotencyRepository) DeleteBefore(before time.Time) (int64, error) {
+	r.Logger.Info("start repository DeleteBefore")
+
+	query := `DELETE FROM idempotency_key WHERE created_at < $1;`
+	res, err := r.DB.Exec(query, before)
+	if err != nil {
+		r.Logger.Error(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"sort"
+	"time"
+)
+
+var ErrLotExpiryMismatch = errors.New("lot already exists with a different expiry date")
+
+// ExpiringLots returns the lots matching filter ordered by expiry date.
+func (r *warehouseRepository) ExpiringLots(filter *model.LotFilter) ([]model.Lot, error) {
+	r.Logger.Info("start repository ExpiringLots")
+
+	query := `SELECT ` + lotColumns + ` FROM lot
+		WHERE total_count > 0 AND expires_on <= $1
+		  AND ($2 = 0 OR warehouse_id = $2)
+		ORDER BY expires_on, warehouse_id, product_code, id;`
+	rows, err := r.DB.Query(query, filter.Before, filter.WarehouseID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := make([]model.Lot, 0)
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		lots = append(lots, *lot)
+	}
+	return lots, rows.Err()
+}
+
+// addLot adds count to total_count and left_count of the lot, creating it with the expiry date
+// if needed, and returns the updated lot. It fails with ErrLotExpiryMismatch if the lot exists
+// with another expiry date.
+func addLot(tx *sql.Tx, warehouseID int, productCode string, lotNumber string, expiresOn *time.Time,
+	count int) (*model.Lot, error) {
+	query := `INSERT INTO lot (warehouse_id, product_code, lot_number, expires_on, total_count, left_count)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (warehouse_id, product_code, lot_number) DO UPDATE
+		SET total_count = lot.total_count + EXCLUDED.total_count,
+		    left_count  = lot.left_count + EXCLUDED.left_count
+		WHERE lot.expires_on IS NOT DISTINCT FROM EXCLUDED.expires_on
+		RETURNING ` + lotColumns + `;`
+	lot, err := scanLot(tx.QueryRow(query, warehouseID, productCode, lotNumber, expiresOn, count))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLotExpiryMismatch
+	}
+	return lot, err
+}
+
+// takeLots takes up to count of the free stock of the product in the warehouse from its lots,
+// earliest expiry first, and returns what was taken from each lot. The stock leaves left_count
+// of the lots, and total_count too if withTotal is set. Expired lots are only drawn if
+// withExpired is set, which is meant for write-offs; reservations and transfers never hand
+// them out. Less than count is taken when the rest of the free stock is not in usable lots.
+func takeLots(tx *sql.Tx, warehouseID int, productCode string, count int, withTotal bool,
+	withExpired bool) ([]model.LotCount, error) {
+	var today *time.Time
+	if !withExpired {
+		date := time.Now().UTC().Truncate(24 * time.Hour)
+		today = &date
+	}
+
+	query := `SELECT id, lot_number, expires_on, left_count FROM lot
+		WHERE warehouse_id = $1 AND product_code = $2 AND left_count > 0
+		  AND ($3::date IS NULL OR expires_on IS NULL OR expires_on >= $3::date)
+		ORDER BY id FOR UPDATE;`
+	rows, err := tx.Query(query, warehouseID, productCode, today)
+	if err != nil {
+		return nil, err
+	}
+	var lots []model.Lot
+	for rows.Next() {
+		lot := model.Lot{}
+		if err = rows.Scan(&lot.ID, &lot.LotNumber, &lot.ExpiresOn, &lot.LeftCount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	taken := drawLots(lots, count, today)
+	for _, lot := range taken {
+		totalDelta := 0
+		if withTotal {
+			totalDelta = lot.Count
+		}
+		query = `UPDATE lot SET left_count = left_count - $1, total_count = total_count - $2 WHERE id = $3;`
+		if _, err = tx.Exec(query, lot.Count, totalDelta, lot.LotID); err != nil {
+			return nil, err
+		}
+	}
+	return taken, nil
+}
+
+// drawLots decides how much of count to take from the free stock of each lot: first expiry
+// first and lots without an expiry date last. Lots with the same expiry are taken in ID order,
+// which is the order of lots. If today is set, lots that expired before it are skipped.
+func drawLots(lots []model.Lot, count int, today *time.Time) []model.LotCount {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i].ExpiresOn, lots[j].ExpiresOn
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		default:
+			return a.Before(*b)
+		}
+	})
+
+	var taken []model.LotCount
+	for _, lot := range lots {
+		if count == 0 {
+			break
+		}
+		if lot.LeftCount <= 0 || today != nil && lot.ExpiresOn != nil && lot.ExpiresOn.Before(*today) {
+			continue
+		}
+		take := min(lot.LeftCount, count)
+		taken = append(taken, model.LotCount{LotID: lot.ID, LotNumber: lot.LotNumber, ExpiresOn: lot.ExpiresOn, Count: take})
+		count -= take
+	}
+	return taken
+}
+
+// reserveLots takes the reservation from the lots of its product and records what it drew from
+// each of them in reservation.Lots.
+func reserveLots(tx *sql.Tx, reservation *model.Reservation) error {
+	lots, err := takeLots(tx, reservation.WarehouseID, reservation.ProductCode, reservation.Count, false, false)
+	if err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		query := `INSERT INTO reservation_lot (reservation_id, lot_id, count) VALUES ($1, $2, $3);`
+		if _, err = tx.Exec(query, reservation.ID, lot.LotID, lot.Count); err != nil {
+			return err
+		}
+	}
+	reservation.Lots = lots
+	return nil
+}
+
+// settleLots applies a reservation leaving the reserved status to the lots it drew from:
+// shipping removes its stock from total_count of the lots, cancelling returns it to their
+// left_count.
+func settleLots(tx *sql.Tx, reservationID int, status string) error {
+	query := ``
+	switch status {
+	case model.StatusShipped:
+		query = `UPDATE lot SET total_count = lot.total_count - rl.count
+			FROM reservation_lot rl WHERE rl.lot_id = lot.id AND rl.reservation_id = $1;`
+	case model.StatusCancelled:
+		query = `UPDATE lot SET left_count = lot.left_count + rl.count
+			FROM reservation_lot rl WHERE rl.lot_id = lot.id AND rl.reservation_id = $1;`
+	default:
+		return nil
+	}
+	_, err := tx.Exec(query, reservationID)
+	return err
+}
+
+// reservationLots returns what the reservation drew from each lot.
+func reservationLots(db *sql.DB, reservationID int) ([]model.LotCount, error) {
+	query := `SELECT l.id, l.lot_number, l.expires_on, rl.count FROM reservation_lot rl
+		JOIN lot l ON l.id = rl.lot_id
+		WHERE rl.reservation_id = $1
+		ORDER BY l.expires_on NULLS LAST, l.id;`
+	rows, err := db.Query(query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []model.LotCount
+	for rows.Next() {
+		lot := model.LotCount{}
+		if err = rows.Scan(&lot.LotID, &lot.LotNumber, &lot.ExpiresOn, &lot.Count); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	return lots, rows.Err()
+}
+
+// transferLots records the lots the stock of the transfer was taken from, so that the
+// destination receives it into lots with the same numbers and expiry dates.
+func transferLots(tx *sql.Tx, transfer *model.Transfer) error {
+	for _, lot := range transfer.Lots {
+		query := `INSERT INTO stock_transfer_lot (transfer_id, lot_number, expires_on, count) VALUES ($1, $2, $3, $4);`
+		if _, err := tx.Exec(query, transfer.ID, lot.LotNumber, lot.ExpiresOn, lot.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transferredLots returns the lots recorded for the transfer by transferLots.
+func transferredLots(tx *sql.Tx, transferID int) ([]model.LotCount, error) {
+	query := `SELECT lot_number, expires_on, count FROM stock_transfer_lot WHERE transfer_id = $1
+		ORDER BY expires_on NULLS LAST, lot_number;`
+	rows, err := tx.Query(query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []model.LotCount
+	for rows.Next() {
+		lot := model.LotCount{}
+		if err = rows.Scan(&lot.LotNumber, &lot.ExpiresOn, &lot.Count); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	return lots, rows.Err()
+}
+
+const lotColumns = `id, warehouse_id, product_code, lot_number, expires_on, total_count, left_count, created_at`
+
+// scanLot scans a row selected with lotColumns.
+func scanLot(row scanner) (*model.Lot, error) {
+	lot := &model.Lot{}
+	err := row.Scan(&lot.ID, &lot.WarehouseID, &lot.ProductCode, &lot.LotNumber, &lot.ExpiresOn, &lot.TotalCount,
+		&lot.LeftCount, &lot.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return lot, nil
+}
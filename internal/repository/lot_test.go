@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"example1/internal/model"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func TestDrawLots(t *testing.T) {
+	expired := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	soon := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// lots returns the lots in ID order, as takeLots selects them.
+	lots := func() []model.Lot {
+		return []model.Lot{
+			{ID: 1, LotNumber: "L-1", LeftCount: 10},
+			{ID: 2, LotNumber: "L-2", ExpiresOn: &later, LeftCount: 10},
+			{ID: 3, LotNumber: "L-3", ExpiresOn: &soon, LeftCount: 10},
+			{ID: 4, LotNumber: "L-4", ExpiresOn: &expired, LeftCount: 5},
+			{ID: 5, LotNumber: "L-5", ExpiresOn: &soon, LeftCount: 10},
+		}
+	}
+
+	testTable := []struct {
+		name     string
+		count    int
+		today    *time.Time
+		expected []model.LotCount
+	}{
+		{
+			name:  "Expired lot skipped",
+			count: 3,
+			today: &today,
+			expected: []model.LotCount{
+				{LotID: 3, LotNumber: "L-3", ExpiresOn: &soon, Count: 3},
+			},
+		},
+		{
+			name:  "Expired lot written off first",
+			count: 3,
+			expected: []model.LotCount{
+				{LotID: 4, LotNumber: "L-4", ExpiresOn: &expired, Count: 3},
+			},
+		},
+		{
+			name:  "Same expiry in ID order",
+			count: 15,
+			today: &today,
+			expected: []model.LotCount{
+				{LotID: 3, LotNumber: "L-3", ExpiresOn: &soon, Count: 10},
+				{LotID: 5, LotNumber: "L-5", ExpiresOn: &soon, Count: 5},
+			},
+		},
+		{
+			name:  "Lot without expiry last",
+			count: 35,
+			today: &today,
+			expected: []model.LotCount{
+				{LotID: 3, LotNumber: "L-3", ExpiresOn: &soon, Count: 10},
+				{LotID: 5, LotNumber: "L-5", ExpiresOn: &soon, Count: 10},
+				{LotID: 2, LotNumber: "L-2", ExpiresOn: &later, Count: 10},
+				{LotID: 1, LotNumber: "L-1", Count: 5},
+			},
+		},
+		{
+			name:  "More than the lots hold",
+			count: 100,
+			today: &today,
+			expected: []model.LotCount{
+				{LotID: 3, LotNumber: "L-3", ExpiresOn: &soon, Count: 10},
+				{LotID: 5, LotNumber: "L-5", ExpiresOn: &soon, Count: 10},
+				{LotID: 2, LotNumber: "L-2", ExpiresOn: &later, Count: 10},
+				{LotID: 1, LotNumber: "L-1", Count: 10},
+			},
+		},
+		{
+			name:  "Write-off of more than the lots hold",
+			count: 100,
+			expected: []model.LotCount{
+				{LotID: 4, LotNumber: "L-4", ExpiresOn: &expired, Count: 5},
+				{LotID: 3, LotNumber: "L-3", ExpiresOn: &soon, Count: 10},
+				{LotID: 5, LotNumber: "L-5", ExpiresOn: &soon, Count: 10},
+				{LotID: 2, LotNumber: "L-2", ExpiresOn: &later, Count: 10},
+				{LotID: 1, LotNumber: "L-1", Count: 10},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, drawLots(lots(), test.count, test.today))
+		})
+	}
+}
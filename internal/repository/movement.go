@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"example1/internal/model"
+)
+
+// insertMovement appends a movement to the stock ledger. It must be called in the transaction
+// that changes the stock.
+func insertMovement(tx *sql.Tx, m *model.StockMovement) error {
+	query := `INSERT INTO stock_movement (warehouse_id, product_code, delta, total_delta, reason, reservation_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at;`
+	return tx.QueryRow(query, m.WarehouseID, m.ProductCode, m.Delta, m.TotalDelta, m.Reason,
+		sql.NullInt64{Int64: int64(m.ReservationID), Valid: m.ReservationID != 0}, nullString(m.UserID)).
+		Scan(&m.ID, &m.CreatedAt)
+}
+
+// ListMovements returns the movements matching filter ordered by ID.
+func (r *warehouseRepository) ListMovements(filter *model.MovementFilter) ([]model.StockMovement, error) {
+	r.Logger.Info("start repository ListMovements")
+
+	query := `SELECT id, warehouse_id, product_code, delta, total_delta, reason, reservation_id, user_id, created_at
+		FROM stock_movement
+		WHERE warehouse_id = $1 AND product_code = $2
+		  AND id > $3
+		  AND ($4::timestamp IS NULL OR created_at >= $4)
+		  AND ($5::timestamp IS NULL OR created_at < $5)
+		ORDER BY id
+		LIMIT $6;`
+	rows, err := r.DB.Query(query, filter.WarehouseID, filter.ProductCode, filter.Cursor,
+		nullTime(filter.From), nullTime(filter.To), filter.Limit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]model.StockMovement, 0)
+	for rows.Next() {
+		m := model.StockMovement{}
+		reservationID := sql.NullInt64{}
+		userID := sql.NullString{}
+		err = rows.Scan(&m.ID, &m.WarehouseID, &m.ProductCode, &m.Delta, &m.TotalDelta, &m.Reason,
+			&reservationID, &userID, &m.CreatedAt)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		m.ReservationID = int(reservationID.Int64)
+		m.UserID = userID.String
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
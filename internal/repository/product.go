@@ -2,10 +2,21 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"example1/internal/model"
 	"example1/pkg/logger"
+	"time"
 )
 
+var ErrNotEnoughLeft = errors.New("not enough product left")
+var ErrInvalidCount = errors.New("count must be positive")
+var ErrReservationExpired = errors.New("reservation has expired")
+var ErrReservationNotExpiring = errors.New("reservation does not expire")
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// errRollback aborts a transaction whose outcome has already been recorded by the caller.
+var errRollback = errors.New("rollback")
+
 type productRepository struct {
 	DB     *sql.DB
 	Logger logger.Logger
@@ -15,24 +26,38 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 	return &productRepository{db, logger.Get()}
 }
 
+//go:generate mockgen -source=product.go -destination=mocks/mock_product.go
+
 type ProductRepository interface {
 	ReserveProduct(reservation *model.Reservation) (*model.Reservation, error)
+	ReserveProducts(reservations []*model.Reservation) ([]error, error)
 	GetLeftCount(uniqueCode string, warehouseId int) (int, error)
-	ChangeLeftCount(uniqueCode string, warehouseId, count int) error
-	DeleteReservation(reservationID int) error
-	GetWarehouseByReservationID(resID int) (int, error)
+	ChangeStatus(reservationID int, status string, userID string) (*model.Reservation, error)
+	GetReservation(resID int) (*model.Reservation, error)
+	ListReservations(filter *model.ReservationFilter) ([]model.Reservation, error)
+	ExtendReservation(reservationID int, by time.Duration, now time.Time) (time.Time, error)
+	ReleaseExpired(now time.Time) ([]model.Reservation, error)
+	Backorder(reservation *model.Reservation, backorder *model.Backorder) error
+	CancelBackorder(backorderID int) (*model.Backorder, error)
+	GetBackorder(backorderID int) (*model.Backorder, error)
+	ListBackorders(filter *model.BackorderFilter) ([]model.Backorder, error)
 }
 
 func (r *productRepository) ReduceCountOfProduct() {
 
 }
 
+// ReserveProduct decrements left_count, creates the reservation row, draws it from the lots of
+// the product earliest expiry first, assigns it units of a serialized product and records the
+// movement in one transaction.
+// The warehouse_product row is locked for the duration, so concurrent reservations of the
+// same product are serialized and can never take more than is left.
 func (r *productRepository) ReserveProduct(reservation *model.Reservation) (*model.Reservation, error) {
 	r.Logger.Info("start repository ReserveProduct")
 
-	query := `INSERT INTO reservation (warehouse_id, product_code, count) VALUES ($1, $2, $3) RETURNING id;`
-	row := r.DB.QueryRow(query, reservation.WarehouseID, reservation.ProductCode, reservation.Count)
-	err := row.Scan(&reservation.ID)
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		return reserve(tx, reservation, false)
+	})
 	if err != nil {
 		r.Logger.Error(err)
 		return nil, err
@@ -40,6 +65,95 @@ func (r *productRepository) ReserveProduct(reservation *model.Reservation) (*mod
 	return reservation, nil
 }
 
+// ReserveProducts reserves all reservations in a single transaction. It returns one error per
+// reservation, nil for those that could be reserved, and commits only if every one succeeded;
+// otherwise nothing is reserved. Lines after the first failure are still attempted so that
+// every failure reason is reported.
+func (r *productRepository) ReserveProducts(reservations []*model.Reservation) ([]error, error) {
+	r.Logger.Info("start repository ReserveProducts")
+
+	errs := make([]error, len(reservations))
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		failed := false
+		for i, reservation := range reservations {
+			errs[i] = reserve(tx, reservation, false)
+			if errs[i] != nil {
+				failed = true
+			}
+		}
+		if failed {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return errs, nil
+}
+
+// reserve takes the reservation from the free stock of its product. Unless it is allocated to a
+// waiting backorder (forBackorder), it may only take the stock the waiting backorders are not
+// queued for, so a new reservation never overtakes the backorder queue.
+func reserve(tx *sql.Tx, reservation *model.Reservation, forBackorder bool) error {
+	if reservation.Count <= 0 {
+		return ErrInvalidCount
+	}
+
+	query := `SELECT wp.left_count, p.serialized FROM warehouse_product wp
+		JOIN product p ON p.unique_code = wp.product_code
+		WHERE wp.product_code = $1 AND wp.warehouse_id = $2 FOR UPDATE OF wp;`
+	left, serialized := 0, false
+	err := tx.QueryRow(query, reservation.ProductCode, reservation.WarehouseID).Scan(&left, &serialized)
+	if err != nil {
+		return err
+	}
+	if !forBackorder {
+		queued, err := queuedCount(tx, reservation.WarehouseID, reservation.ProductCode)
+		if err != nil {
+			return err
+		}
+		left = freeCount(left, queued)
+	}
+
+	if left < reservation.Count {
+		return ErrNotEnoughLeft
+	}
+
+	query = `UPDATE warehouse_product SET left_count = left_count - $1 WHERE product_code = $2 AND warehouse_id = $3;`
+	_, err = tx.Exec(query, reservation.Count, reservation.ProductCode, reservation.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	reservation.Status = model.StatusReserved
+	query = `INSERT INTO reservation (warehouse_id, product_code, count, expires_at, status, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at;`
+	err = tx.QueryRow(query, reservation.WarehouseID, reservation.ProductCode, reservation.Count, reservation.ExpiresAt,
+		reservation.Status, nullString(reservation.UserID)).Scan(&reservation.ID, &reservation.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err = reserveLots(tx, reservation); err != nil {
+		return err
+	}
+	if serialized {
+		if err = reserveSerials(tx, reservation); err != nil {
+			return err
+		}
+	}
+
+	return insertMovement(tx, &model.StockMovement{
+		WarehouseID:   reservation.WarehouseID,
+		ProductCode:   reservation.ProductCode,
+		Delta:         -reservation.Count,
+		Reason:        model.MovementReserve,
+		ReservationID: reservation.ID,
+		UserID:        reservation.UserID,
+	})
+}
+
 func (r *productRepository) GetLeftCount(uniqueCode string, warehouseId int) (int, error) {
 	r.Logger.Info("start repository GetLeftCount")
 
@@ -53,39 +167,239 @@ func (r *productRepository) GetLeftCount(uniqueCode string, warehouseId int) (in
 	return count, nil
 }
 
-func (r *productRepository) ChangeLeftCount(uniqueCode string, warehouseID, count int) error {
-	r.Logger.Info("start repository ChangeLeftCount")
+// ChangeStatus moves the reservation to the given status, applying its effect on stock and
+// recording the transition in reservation_history and the stock ledger. Shipping removes the
+// reserved count from total_count, cancelling returns it to left_count and offers it to
+// waiting backorders; the lots the reservation drew from and its units are changed the same
+// way. If the transition is not allowed, the reservation is returned as is together with
+// ErrInvalidTransition.
+func (r *productRepository) ChangeStatus(reservationID int, status string, userID string) (*model.Reservation, error) {
+	r.Logger.Info("start repository ChangeStatus")
 
-	query := `UPDATE warehouse_product SET left_count = $1 WHERE product_code = $2 AND warehouse_id = $3;`
-	_, err := r.DB.Exec(query, count, uniqueCode, warehouseID)
+	re := &model.Reservation{ID: reservationID}
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		return transition(tx, re, status, userID)
+	})
 	if err != nil {
 		r.Logger.Error(err)
+		if errors.Is(err, ErrInvalidTransition) {
+			return re, err
+		}
+		return nil, err
+	}
+	return re, nil
+}
+
+// transition moves the reservation with reservation.ID to the given status. It fills in the
+// rest of reservation from its row, so on ErrInvalidTransition it holds the current status.
+func transition(tx *sql.Tx, reservation *model.Reservation, status string, userID string) error {
+	query := `SELECT ` + reservationColumns + ` FROM reservation WHERE id = $1 FOR UPDATE;`
+	re, err := scanReservation(tx.QueryRow(query, reservation.ID))
+	if err != nil {
+		return err
+	}
+	*reservation = *re
+
+	if !model.CanTransition(reservation.Status, status) {
+		return ErrInvalidTransition
+	}
+
+	movement := &model.StockMovement{
+		WarehouseID:   reservation.WarehouseID,
+		ProductCode:   reservation.ProductCode,
+		ReservationID: reservation.ID,
+		UserID:        userID,
+	}
+	switch status {
+	case model.StatusShipped:
+		movement.TotalDelta = -reservation.Count
+		movement.Reason = model.MovementShip
+	case model.StatusCancelled:
+		movement.Delta = reservation.Count
+		movement.Reason = model.MovementFree
+	}
+
+	if movement.Reason != "" {
+		query = `UPDATE warehouse_product SET left_count = left_count + $1, total_count = total_count + $2
+			WHERE product_code = $3 AND warehouse_id = $4;`
+		_, err = tx.Exec(query, movement.Delta, movement.TotalDelta, reservation.ProductCode, reservation.WarehouseID)
+		if err != nil {
+			return err
+		}
+		if err = insertMovement(tx, movement); err != nil {
+			return err
+		}
+		if err = settleLots(tx, reservation.ID, status); err != nil {
+			return err
+		}
+		if err = settleSerials(tx, reservation.ID, status, userID); err != nil {
+			return err
+		}
+	}
+	if status == model.StatusCancelled {
+		if _, err = allocateBackorders(tx, reservation.WarehouseID, reservation.ProductCode); err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE reservation SET status = $1 WHERE id = $2;`
+	_, err = tx.Exec(query, status, reservation.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO reservation_history (reservation_id, from_status, to_status, user_id) VALUES ($1, $2, $3, $4);`
+	_, err = tx.Exec(query, reservation.ID, reservation.Status, status, nullString(userID))
+	if err != nil {
 		return err
 	}
+
+	reservation.Status = status
 	return nil
 }
 
-func (r *productRepository) DeleteReservation(reservationID int) error {
-	r.Logger.Info("start repository DeleteReservation")
+// ExtendReservation pushes the expiry of a reservation that has not lapsed yet back by the
+// given duration and returns the new expiry. Only reservations in the reserved status expire.
+func (r *productRepository) ExtendReservation(reservationID int, by time.Duration, now time.Time) (time.Time, error) {
+	r.Logger.Info("start repository ExtendReservation")
+
+	var expiresAt *time.Time
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT expires_at, status FROM reservation WHERE id = $1 FOR UPDATE;`
+		status := ""
+		err := tx.QueryRow(query, reservationID).Scan(&expiresAt, &status)
+		if err != nil {
+			return err
+		}
+
+		if expiresAt == nil || status != model.StatusReserved {
+			return ErrReservationNotExpiring
+		}
+		if !expiresAt.After(now) {
+			return ErrReservationExpired
+		}
 
-	query := `DELETE FROM reservation WHERE id = $1;`
-	_, err := r.DB.Exec(query, reservationID)
+		extended := expiresAt.Add(by)
+		expiresAt = &extended
+		query = `UPDATE reservation SET expires_at = $1 WHERE id = $2;`
+		_, err = tx.Exec(query, expiresAt, reservationID)
+		return err
+	})
 	if err != nil {
 		r.Logger.Error(err)
-		return err
+		return time.Time{}, err
 	}
-	return nil
+	return *expiresAt, nil
 }
 
-func (r *productRepository) GetWarehouseByReservationID(resID int) (int, error) {
-	r.Logger.Info("start repository GetWarehouseByReservationID")
+// ReleaseExpired cancels every reserved reservation that expired before now and returns the
+// released reservations. Reservations locked by a concurrent transaction are left for the next run.
+func (r *productRepository) ReleaseExpired(now time.Time) ([]model.Reservation, error) {
+	r.Logger.Info("start repository ReleaseExpired")
 
-	query := `SELECT (warehouse_id) FROM reservation WHERE id = $1;`
-	id := 0
-	err := r.DB.QueryRow(query, resID).Scan(&id)
+	var released []model.Reservation
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT id FROM reservation WHERE status = $1 AND expires_at < $2 FOR UPDATE SKIP LOCKED;`
+		rows, err := tx.Query(query, model.StatusReserved, now)
+		if err != nil {
+			return err
+		}
+
+		var ids []int
+		for rows.Next() {
+			id := 0
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			re := model.Reservation{ID: id}
+			if err = transition(tx, &re, model.StatusCancelled, ""); err != nil {
+				return err
+			}
+			released = append(released, re)
+		}
+		return nil
+	})
 	if err != nil {
 		r.Logger.Error(err)
-		return 0, err
+		return nil, err
+	}
+	return released, nil
+}
+
+func (r *productRepository) GetReservation(resID int) (*model.Reservation, error) {
+	r.Logger.Info("start repository GetReservation")
+
+	query := `SELECT ` + reservationColumns + ` FROM reservation WHERE id = $1;`
+	re, err := scanReservation(r.DB.QueryRow(query, resID))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	re.Lots, err = reservationLots(r.DB, re.ID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	re.SerialNumbers, err = reservationSerials(r.DB, re.ID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return re, nil
+}
+
+// ListReservations returns the reservations matching filter ordered by ID.
+func (r *productRepository) ListReservations(filter *model.ReservationFilter) ([]model.Reservation, error) {
+	r.Logger.Info("start repository ListReservations")
+
+	query := `SELECT ` + reservationColumns + ` FROM reservation
+		WHERE id > $1
+		  AND ($2 = 0 OR warehouse_id = $2)
+		  AND ($3 = '' OR product_code = $3)
+		  AND ($4 = '' OR user_id = $4)
+		  AND ($5 = '' OR status = $5)
+		ORDER BY id
+		LIMIT $6;`
+	rows, err := r.DB.Query(query, filter.Cursor, filter.WarehouseID, filter.ProductCode, filter.UserID,
+		filter.Status, filter.Limit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := make([]model.Reservation, 0)
+	for rows.Next() {
+		re, err := scanReservation(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		reservations = append(reservations, *re)
+	}
+	return reservations, rows.Err()
+}
+
+const reservationColumns = `id, warehouse_id, product_code, count, expires_at, status, user_id, created_at`
+
+// scanReservation scans a row selected with reservationColumns.
+func scanReservation(row scanner) (*model.Reservation, error) {
+	re := &model.Reservation{}
+	userID := sql.NullString{}
+	err := row.Scan(&re.ID, &re.WarehouseID, &re.ProductCode, &re.Count, &re.ExpiresAt, &re.Status, &userID,
+		&re.CreatedAt)
+	if err != nil {
+		return nil, err
 	}
-	return id, nil
+	re.UserID = userID.String
+	return re, nil
 }
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"example1/internal/model"
+)
+
+// Discrepancies returns every warehouse_product row whose left_count is not total_count minus
+// the count of its live reservations, ordered by warehouse and product.
+func (r *warehouseRepository) Discrepancies() ([]model.Discrepancy, error) {
+	r.Logger.Info("start repository Discrepancies")
+
+	query := `SELECT warehouse_id, product_code, total_count, left_count, reserved FROM (
+			SELECT wp.warehouse_id, wp.product_code, wp.total_count, wp.left_count,
+			       (SELECT COALESCE(SUM(re.count), 0) FROM reservation re
+			        WHERE re.warehouse_id = wp.warehouse_id AND re.product_code = wp.product_code
+			          AND re.status IN ($1, $2)) AS reserved
+			FROM warehouse_product wp
+		) AS stock
+		WHERE left_count <> total_count - reserved
+		ORDER BY warehouse_id, product_code;`
+	rows, err := r.DB.Query(query, model.StatusReserved, model.StatusConfirmed)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := make([]model.Discrepancy, 0)
+	for rows.Next() {
+		d := model.Discrepancy{}
+		err = rows.Scan(&d.WarehouseID, &d.ProductCode, &d.TotalCount, &d.LeftCount, &d.Reserved)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		d.Expected = d.TotalCount - d.Reserved
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies, rows.Err()
+}
+
+// FixDiscrepancy sets left_count of the product in the warehouse to total_count minus the count
+// of its live reservations and records the correction in the stock ledger. The counts are read
+// again under a row lock, so d is updated to the state that was corrected. A row that turns out
+// to be consistent, or whose live reservations exceed total_count, is left unchanged and
+// d.Fixed stays false.
+func (r *warehouseRepository) FixDiscrepancy(d *model.Discrepancy, userID string) error {
+	r.Logger.Info("start repository FixDiscrepancy")
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT total_count, left_count FROM warehouse_product
+			WHERE warehouse_id = $1 AND product_code = $2 FOR UPDATE;`
+		err := tx.QueryRow(query, d.WarehouseID, d.ProductCode).Scan(&d.TotalCount, &d.LeftCount)
+		if err != nil {
+			return err
+		}
+
+		query = `SELECT COALESCE(SUM(count), 0) FROM reservation
+			WHERE warehouse_id = $1 AND product_code = $2 AND status IN ($3, $4);`
+		err = tx.QueryRow(query, d.WarehouseID, d.ProductCode, model.StatusReserved, model.StatusConfirmed).
+			Scan(&d.Reserved)
+		if err != nil {
+			return err
+		}
+		d.Expected = d.TotalCount - d.Reserved
+
+		delta := d.Difference()
+		if delta == 0 || d.Expected < 0 {
+			return nil
+		}
+
+		query = `UPDATE warehouse_product SET left_count = $1 WHERE warehouse_id = $2 AND product_code = $3;`
+		_, err = tx.Exec(query, d.Expected, d.WarehouseID, d.ProductCode)
+		if err != nil {
+			return err
+		}
+		err = insertMovement(tx, &model.StockMovement{
+			WarehouseID: d.WarehouseID,
+			ProductCode: d.ProductCode,
+			Delta:       delta,
+			Reason:      model.MovementReconcile,
+			UserID:      userID,
+		})
+		if err != nil {
+			return err
+		}
+		d.Fixed = true
+
+		if delta > 0 {
+			_, err = allocateBackorders(tx, d.WarehouseID, d.ProductCode)
+		}
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
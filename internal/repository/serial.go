@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"github.com/lib/pq"
+	"sort"
+)
+
+var ErrSerialCountMismatch = errors.New("serial numbers do not match the count")
+var ErrNotSerialized = errors.New("product is not serialized")
+var ErrSerialExists = errors.New("serial number is already registered")
+var ErrSerialNotAvailable = errors.New("serial number is not available in the warehouse")
+
+// SerialNumbers returns the units with the given serial number together with their history,
+// of the product if productCode is set and of any product otherwise.
+func (r *warehouseRepository) SerialNumbers(serialNumber string, productCode string) ([]model.SerialNumber, error) {
+	r.Logger.Info("start repository SerialNumbers")
+
+	query := `SELECT ` + serialColumns + ` FROM serial_number
+		WHERE serial_number = $1 AND ($2 = '' OR product_code = $2)
+		ORDER BY product_code;`
+	rows, err := r.DB.Query(query, serialNumber, productCode)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	serials := make([]model.SerialNumber, 0)
+	for rows.Next() {
+		serial, err := scanSerial(rows)
+		if err != nil {
+			rows.Close()
+			r.Logger.Error(err)
+			return nil, err
+		}
+		serials = append(serials, *serial)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+
+	for i := range serials {
+		serials[i].History, err = r.serialHistory(serials[i].ID)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+	}
+	return serials, nil
+}
+
+// serialHistory returns the events of the unit in the order they happened.
+func (r *warehouseRepository) serialHistory(serialID int) ([]model.SerialEvent, error) {
+	query := `SELECT status, warehouse_id, reservation_id, transfer_id, user_id, created_at
+		FROM serial_number_history WHERE serial_number_id = $1 ORDER BY id;`
+	rows, err := r.DB.Query(query, serialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]model.SerialEvent, 0)
+	for rows.Next() {
+		event := model.SerialEvent{}
+		reservationID, transferID, userID := sql.NullInt64{}, sql.NullInt64{}, sql.NullString{}
+		err = rows.Scan(&event.Status, &event.WarehouseID, &reservationID, &transferID, &userID, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		event.ReservationID = int(reservationID.Int64)
+		event.TransferID = int(transferID.Int64)
+		event.UserID = userID.String
+		history = append(history, event)
+	}
+	return history, rows.Err()
+}
+
+// checkSerials returns an error unless the receipt has a serial number for every unit of a
+// serialized product, or none for a product that is not serialized.
+func checkSerials(receipt *model.Receipt, serialized bool) error {
+	if !serialized && len(receipt.SerialNumbers) != 0 {
+		return ErrNotSerialized
+	}
+	if serialized && len(receipt.SerialNumbers) != receipt.Count {
+		return ErrSerialCountMismatch
+	}
+	return nil
+}
+
+// registerSerials adds the received units to the warehouse as available. A serial number that
+// was shipped or written off before is registered again; one that is still in stock or in
+// transit fails with ErrSerialExists.
+func registerSerials(tx *sql.Tx, receipt *model.Receipt) error {
+	event := &model.SerialEvent{UserID: receipt.UserID}
+	for _, serialNumber := range receipt.SerialNumbers {
+		query := `INSERT INTO serial_number (product_code, serial_number, warehouse_id, status) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_code, serial_number) DO UPDATE
+			SET warehouse_id = EXCLUDED.warehouse_id, status = EXCLUDED.status, reservation_id = NULL, transfer_id = NULL
+			WHERE serial_number.status IN ($5, $6)
+			RETURNING id, serial_number, status, warehouse_id;`
+		moved, err := moveSerials(tx, event, query, receipt.ProductCode, serialNumber, receipt.WarehouseID,
+			model.SerialAvailable, model.SerialShipped, model.SerialWrittenOff)
+		if err != nil {
+			return err
+		}
+		if len(moved) == 0 {
+			return ErrSerialExists
+		}
+	}
+	return nil
+}
+
+// writeOffSerials marks the units listed by the adjustment written off. They must be available
+// in its warehouse, otherwise it fails with ErrSerialNotAvailable.
+func writeOffSerials(tx *sql.Tx, adjustment *model.Adjustment) error {
+	query := `UPDATE serial_number SET status = $1
+		WHERE product_code = $2 AND warehouse_id = $3 AND status = $4 AND serial_number = ANY($5)
+		RETURNING id, serial_number, status, warehouse_id;`
+	event := &model.SerialEvent{UserID: adjustment.AppliedBy()}
+	serials, err := moveSerials(tx, event, query, model.SerialWrittenOff, adjustment.ProductCode,
+		adjustment.WarehouseID, model.SerialAvailable, pq.Array(adjustment.SerialNumbers))
+	if err != nil {
+		return err
+	}
+	if len(serials) < len(adjustment.SerialNumbers) {
+		return ErrSerialNotAvailable
+	}
+	return nil
+}
+
+// reserveSerials assigns available units of a serialized product in the warehouse to the
+// reservation, oldest first, and lists them in reservation.SerialNumbers.
+func reserveSerials(tx *sql.Tx, reservation *model.Reservation) error {
+	query := `UPDATE serial_number SET status = $1, reservation_id = $2
+		WHERE id IN (SELECT id FROM serial_number
+		             WHERE product_code = $3 AND warehouse_id = $4 AND status = $5
+		             ORDER BY id LIMIT $6 FOR UPDATE)
+		RETURNING id, serial_number, status, warehouse_id;`
+	event := &model.SerialEvent{ReservationID: reservation.ID, UserID: reservation.UserID}
+	serials, err := moveSerials(tx, event, query, model.SerialReserved, reservation.ID, reservation.ProductCode,
+		reservation.WarehouseID, model.SerialAvailable, reservation.Count)
+	if err != nil {
+		return err
+	}
+	if len(serials) < reservation.Count {
+		return ErrNotEnoughLeft
+	}
+	reservation.SerialNumbers = serials
+	return nil
+}
+
+// settleSerials applies a reservation leaving the reserved status to its units: shipping
+// marks them shipped, cancelling returns them to the available ones of the warehouse.
+func settleSerials(tx *sql.Tx, reservationID int, status string, userID string) error {
+	query := ``
+	switch status {
+	case model.StatusShipped:
+		query = `UPDATE serial_number SET status = $1 WHERE reservation_id = $2 AND status = $3
+			RETURNING id, serial_number, status, warehouse_id;`
+		status = model.SerialShipped
+	case model.StatusCancelled:
+		query = `UPDATE serial_number SET status = $1, reservation_id = NULL WHERE reservation_id = $2 AND status = $3
+			RETURNING id, serial_number, status, warehouse_id;`
+		status = model.SerialAvailable
+	default:
+		return nil
+	}
+	event := &model.SerialEvent{ReservationID: reservationID, UserID: userID}
+	_, err := moveSerials(tx, event, query, status, reservationID, model.SerialReserved)
+	return err
+}
+
+// reservationSerials returns the serial numbers of the units held or shipped by the reservation.
+func reservationSerials(db *sql.DB, reservationID int) ([]string, error) {
+	query := `SELECT serial_number FROM serial_number WHERE reservation_id = $1 ORDER BY id;`
+	rows, err := db.Query(query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serials []string
+	for rows.Next() {
+		serial := ""
+		if err = rows.Scan(&serial); err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+	return serials, rows.Err()
+}
+
+// sendSerials puts the oldest available units of a serialized product in the source warehouse
+// in transit with the transfer and lists them in transfer.SerialNumbers.
+func sendSerials(tx *sql.Tx, transfer *model.Transfer) error {
+	query := `SELECT serialized FROM product WHERE unique_code = $1;`
+	serialized := false
+	if err := tx.QueryRow(query, transfer.ProductCode).Scan(&serialized); err != nil || !serialized {
+		return err
+	}
+
+	query = `UPDATE serial_number SET status = $1, transfer_id = $2
+		WHERE id IN (SELECT id FROM serial_number
+		             WHERE product_code = $3 AND warehouse_id = $4 AND status = $5
+		             ORDER BY id LIMIT $6 FOR UPDATE)
+		RETURNING id, serial_number, status, warehouse_id;`
+	event := &model.SerialEvent{TransferID: transfer.ID, UserID: transfer.CreatedBy}
+	serials, err := moveSerials(tx, event, query, model.SerialInTransit, transfer.ID, transfer.ProductCode,
+		transfer.FromWarehouseID, model.SerialAvailable, transfer.Count)
+	if err != nil {
+		return err
+	}
+	if len(serials) < transfer.Count {
+		return ErrNotEnoughLeft
+	}
+	transfer.SerialNumbers = serials
+	return nil
+}
+
+// receiveSerials makes the units in transit with the transfer available in its destination
+// warehouse and lists them in transfer.SerialNumbers.
+func receiveSerials(tx *sql.Tx, transfer *model.Transfer, userID string) error {
+	query := `UPDATE serial_number SET status = $1, warehouse_id = $2, transfer_id = NULL
+		WHERE transfer_id = $3 AND status = $4
+		RETURNING id, serial_number, status, warehouse_id;`
+	event := &model.SerialEvent{TransferID: transfer.ID, UserID: userID}
+	serials, err := moveSerials(tx, event, query, model.SerialAvailable, transfer.ToWarehouseID, transfer.ID,
+		model.SerialInTransit)
+	if err != nil {
+		return err
+	}
+	transfer.SerialNumbers = serials
+	return nil
+}
+
+// moveSerials runs a query changing units that returns their id, serial number, status and
+// warehouse, and records the change in their history as made by the reservation or transfer
+// of event. It returns the serial numbers of the units in ID order.
+func moveSerials(tx *sql.Tx, event *model.SerialEvent, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var moved []model.SerialNumber
+	for rows.Next() {
+		serial := model.SerialNumber{}
+		if err = rows.Scan(&serial.ID, &serial.SerialNumber, &serial.Status, &serial.WarehouseID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		moved = append(moved, serial)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(moved, func(i, j int) bool { return moved[i].ID < moved[j].ID })
+
+	serials := make([]string, 0, len(moved))
+	for _, serial := range moved {
+		query = `INSERT INTO serial_number_history (serial_number_id, status, warehouse_id, reservation_id, transfer_id, user_id)
+			VALUES ($1, $2, $3, $4, $5, $6);`
+		_, err = tx.Exec(query, serial.ID, serial.Status, serial.WarehouseID, nullInt(event.ReservationID),
+			nullInt(event.TransferID), nullString(event.UserID))
+		if err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial.SerialNumber)
+	}
+	return serials, nil
+}
+
+const serialColumns = `id, product_code, serial_number, warehouse_id, status, reservation_id, transfer_id, created_at`
+
+// scanSerial scans a row selected with serialColumns.
+func scanSerial(row scanner) (*model.SerialNumber, error) {
+	serial := &model.SerialNumber{}
+	reservationID, transferID := sql.NullInt64{}, sql.NullInt64{}
+	err := row.Scan(&serial.ID, &serial.ProductCode, &serial.SerialNumber, &serial.WarehouseID, &serial.Status,
+		&reservationID, &transferID, &serial.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	serial.ReservationID = int(reservationID.Int64)
+	serial.TransferID = int(transferID.Int64)
+	return serial, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"example1/internal/model"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestCheckSerials(t *testing.T) {
+	testTable := []struct {
+		name        string
+		receipt     model.Receipt
+		serialized  bool
+		expectedErr error
+	}{
+		{
+			name:       "Serial number for every unit",
+			receipt:    model.Receipt{Count: 2, SerialNumbers: []string{"SN-1", "SN-2"}},
+			serialized: true,
+		},
+		{
+			name:        "Fewer serial numbers than units",
+			receipt:     model.Receipt{Count: 3, SerialNumbers: []string{"SN-1", "SN-2"}},
+			serialized:  true,
+			expectedErr: ErrSerialCountMismatch,
+		},
+		{
+			name:        "Serialized without serial numbers",
+			receipt:     model.Receipt{Count: 1},
+			serialized:  true,
+			expectedErr: ErrSerialCountMismatch,
+		},
+		{
+			name:    "Not serialized",
+			receipt: model.Receipt{Count: 1},
+		},
+		{
+			name:        "Serial numbers for a product that is not serialized",
+			receipt:     model.Receipt{Count: 1, SerialNumbers: []string{"SN-1"}},
+			expectedErr: ErrNotSerialized,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, checkSerials(&test.receipt, test.serialized))
+		})
+	}
+}
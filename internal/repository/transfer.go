@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/model"
+	"time"
+)
+
+var ErrTransferReceived = errors.New("transfer already received")
+
+// TransferProduct moves transfer.Count of the product out of the source warehouse, taking it
+// from its lots earliest expiry first together with its oldest units if the product is
+// serialized, and records the transfer. Unless inTransit is set, the stock is added to the
+// destination warehouse in the same transaction; otherwise it stays in transit until
+// ReceiveTransfer and its volume is held against the capacity of the destination. Either way
+// the transfer fails with ErrCapacityExceeded if the destination cannot hold the stock now.
+func (r *warehouseRepository) TransferProduct(transfer *model.Transfer, inTransit bool) error {
+	r.Logger.Info("start repository TransferProduct")
+
+	if transfer.Count <= 0 {
+		return ErrInvalidCount
+	}
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		// Lock both rows in warehouse order so that opposite transfers cannot deadlock.
+		query := `SELECT warehouse_id, left_count FROM warehouse_product
+			WHERE product_code = $1 AND warehouse_id IN ($2, $3)
+			ORDER BY warehouse_id FOR UPDATE;`
+		rows, err := tx.Query(query, transfer.ProductCode, transfer.FromWarehouseID, transfer.ToWarehouseID)
+		if err != nil {
+			return err
+		}
+		left, found := 0, false
+		for rows.Next() {
+			warehouseID, count := 0, 0
+			if err = rows.Scan(&warehouseID, &count); err != nil {
+				rows.Close()
+				return err
+			}
+			if warehouseID == transfer.FromWarehouseID {
+				left, found = count, true
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		if !found {
+			return sql.ErrNoRows
+		}
+		if left < transfer.Count {
+			return ErrNotEnoughLeft
+		}
+
+		_, err = addStock(tx, transfer.FromWarehouseID, transfer.ProductCode, -transfer.Count)
+		if err != nil {
+			return err
+		}
+		transfer.Lots, err = takeLots(tx, transfer.FromWarehouseID, transfer.ProductCode, transfer.Count, true, false)
+		if err != nil {
+			return err
+		}
+		err = insertMovement(tx, &model.StockMovement{
+			WarehouseID: transfer.FromWarehouseID,
+			ProductCode: transfer.ProductCode,
+			Delta:       -transfer.Count,
+			TotalDelta:  -transfer.Count,
+			Reason:      model.MovementTransfer,
+			UserID:      transfer.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		transfer.Status = model.TransferInTransit
+		query = `INSERT INTO stock_transfer (from_warehouse_id, to_warehouse_id, product_code, count, status, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at;`
+		err = tx.QueryRow(query, transfer.FromWarehouseID, transfer.ToWarehouseID, transfer.ProductCode, transfer.Count,
+			transfer.Status, nullString(transfer.CreatedBy)).Scan(&transfer.ID, &transfer.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err = transferLots(tx, transfer); err != nil {
+			return err
+		}
+		if err = sendSerials(tx, transfer); err != nil {
+			return err
+		}
+
+		if err = checkCapacity(tx, transfer.ToWarehouseID); err != nil {
+			return err
+		}
+		if inTransit {
+			return nil
+		}
+		return receiveTransfer(tx, transfer, transfer.CreatedBy)
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// ReceiveTransfer adds the stock of an in-transit transfer to its destination warehouse.
+func (r *warehouseRepository) ReceiveTransfer(transferID int, userID string) (*model.Transfer, error) {
+	r.Logger.Info("start repository ReceiveTransfer")
+
+	transfer := &model.Transfer{}
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT id, from_warehouse_id, to_warehouse_id, product_code, count, status, created_by, created_at
+			FROM stock_transfer WHERE id = $1 FOR UPDATE;`
+		createdBy := sql.NullString{}
+		err := tx.QueryRow(query, transferID).Scan(&transfer.ID, &transfer.FromWarehouseID, &transfer.ToWarehouseID,
+			&transfer.ProductCode, &transfer.Count, &transfer.Status, &createdBy, &transfer.CreatedAt)
+		if err != nil {
+			return err
+		}
+		transfer.CreatedBy = createdBy.String
+
+		if transfer.Status != model.TransferInTransit {
+			return ErrTransferReceived
+		}
+		transfer.Lots, err = transferredLots(tx, transfer.ID)
+		if err != nil {
+			return err
+		}
+		return receiveTransfer(tx, transfer, userID)
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// GetTransfer returns the transfer with the given ID.
+func (r *warehouseRepository) GetTransfer(transferID int) (*model.Transfer, error) {
+	r.Logger.Info("start repository GetTransfer")
+
+	query := `SELECT id, from_warehouse_id, to_warehouse_id, product_code, count, status FROM stock_transfer WHERE id = $1;`
+	transfer := &model.Transfer{}
+	err := r.DB.QueryRow(query, transferID).Scan(&transfer.ID, &transfer.FromWarehouseID, &transfer.ToWarehouseID,
+		&transfer.ProductCode, &transfer.Count, &transfer.Status)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// receiveTransfer adds the stock of the transfer to its destination warehouse, into lots with
+// the numbers and expiry dates it was taken from, and makes its units available there. The
+// capacity is not checked again: the volume of the transfer was held against it since the
+// transfer was made.
+func receiveTransfer(tx *sql.Tx, transfer *model.Transfer, userID string) error {
+	_, err := addStock(tx, transfer.ToWarehouseID, transfer.ProductCode, transfer.Count)
+	if err != nil {
+		return err
+	}
+	for _, lot := range transfer.Lots {
+		_, err = addLot(tx, transfer.ToWarehouseID, transfer.ProductCode, lot.LotNumber, lot.ExpiresOn, lot.Count)
+		if err != nil {
+			return err
+		}
+	}
+	if err = receiveSerials(tx, transfer, userID); err != nil {
+		return err
+	}
+	err = insertMovement(tx, &model.StockMovement{
+		WarehouseID: transfer.ToWarehouseID,
+		ProductCode: transfer.ProductCode,
+		Delta:       transfer.Count,
+		TotalDelta:  transfer.Count,
+		Reason:      model.MovementTransfer,
+		UserID:      userID,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = allocateBackorders(tx, transfer.ToWarehouseID, transfer.ProductCode); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	transfer.Status = model.TransferReceived
+	transfer.ReceivedBy = userID
+	transfer.ReceivedAt = &now
+	query := `UPDATE stock_transfer SET status = $1, received_by = $2, received_at = $3 WHERE id = $4;`
+	_, err = tx.Exec(query, transfer.Status, nullString(userID), now, transfer.ID)
+	return err
+}
@@ -2,9 +2,16 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"example1/internal/model"
 	log "example1/pkg/logger"
+	"fmt"
+	"strings"
 )
 
+var ErrWarehouseExists = errors.New("warehouse with this name already exists")
+var ErrCapacityExceeded = errors.New("warehouse capacity exceeded")
+
 type warehouseRepository struct {
 	DB     *sql.DB
 	Logger log.Logger
@@ -14,9 +21,41 @@ func NewWarehouseRepository(db *sql.DB) WarehouseRepository {
 	return &warehouseRepository{db, log.Get()}
 }
 
+//go:generate mockgen -source=warehouse.go -destination=mocks/mock_warehouse.go
+
 type WarehouseRepository interface {
 	CheckAvailable(warehouseID int) (bool, error)
-	AllProducts(warehouseID int) ([]string, error)
+	AllProducts(filter *model.ProductStockFilter) ([]model.ProductStock, error)
+	ListMovements(filter *model.MovementFilter) ([]model.StockMovement, error)
+	ReceiveProduct(receipt *model.Receipt) (*model.WarehouseProduct, *model.Lot, error)
+	TransferProduct(transfer *model.Transfer, inTransit bool) error
+	ReceiveTransfer(transferID int, userID string) (*model.Transfer, error)
+	GetTransfer(transferID int) (*model.Transfer, error)
+	GetStock(warehouseID int, productCode string) (*model.WarehouseProduct, error)
+	SetReorderThreshold(warehouseID int, productCode string, threshold int) (*model.WarehouseProduct, error)
+	LowStock() ([]model.WarehouseProduct, error)
+	Discrepancies() ([]model.Discrepancy, error)
+	FixDiscrepancy(d *model.Discrepancy, userID string) error
+	CreateWarehouse(warehouse *model.Warehouse, userID string) error
+	UpdateWarehouse(warehouseID int, name string, capacity *int) (*model.Warehouse, error)
+	GetWarehouse(warehouseID int) (*model.Warehouse, error)
+	ListWarehouses() ([]model.Warehouse, error)
+	SetAvailability(change *model.AvailabilityChange) (*model.Warehouse, error)
+	AvailabilityHistory(warehouseID int) ([]model.AvailabilityChange, error)
+	Utilization() ([]model.Utilization, error)
+	Availability(productCodes []string) ([]model.WarehouseProduct, error)
+	ExpiringLots(filter *model.LotFilter) ([]model.Lot, error)
+	SerialNumbers(serialNumber string, productCode string) ([]model.SerialNumber, error)
+	CreateAdjustment(adjustment *model.Adjustment) error
+	ReviewAdjustment(adjustmentID int, status string, userID string) (*model.Adjustment, error)
+	ListAdjustments(filter *model.AdjustmentFilter) ([]model.Adjustment, error)
+	OpenCycleCount(count *model.CycleCount) error
+	GetCycleCount(countID int) (*model.CycleCount, error)
+	CountProduct(line *model.CycleCountLine) error
+	FreezeCycleCount(countID int) (*model.CycleCount, error)
+	SettleCountLine(countID int, productCode string, approved bool, userID string) (*model.CycleCountLine,
+		*model.Adjustment, error)
+	CloseCycleCount(countID int, userID string) (*model.CycleCount, error)
 }
 
 func (r *warehouseRepository) CheckAvailable(warehouseID int) (bool, error) {
@@ -30,26 +69,450 @@ func (r *warehouseRepository) CheckAvailable(warehouseID int) (bool, error) {
 	return available, nil
 }
 
-func (r *warehouseRepository) AllProducts(warehouseID int) ([]string, error) {
-	query := `SELECT (product_code) FROM warehouse_product WHERE warehouse_id = $1;`
-	var codes []string
-	rows, err := r.DB.Query(query, warehouseID)
+// AllProducts returns the products of the warehouse matching filter with their catalog data and
+// stock levels, in the order requested by filter. Products missing from the catalog are listed
+// with an empty name and zero size.
+func (r *warehouseRepository) AllProducts(filter *model.ProductStockFilter) ([]model.ProductStock, error) {
+	r.Logger.Info("start repository AllProducts")
+
+	column, ok := productStockSortColumns[filter.Sort]
+	if !ok {
+		column = productStockSortColumns[model.SortByCode]
+	}
+	order, compare := "ASC", ">"
+	if filter.Desc {
+		order, compare = "DESC", "<"
+	}
+
+	args := []any{filter.WarehouseID, model.StatusReserved, model.StatusConfirmed, likeEscaper.Replace(filter.CodePrefix),
+		likeEscaper.Replace(filter.Name), filter.InStock, filter.Limit}
+	after := ""
+	if filter.After != nil {
+		after = fmt.Sprintf("AND (%s, product_code) %s ($8, $9)", column, compare)
+		args = append(args, filter.After.Value, filter.After.ProductCode)
+	}
+
+	query := `SELECT product_code, name, size, total_count, left_count, reserved FROM (
+			SELECT wp.product_code, COALESCE(p.name, '') AS name, COALESCE(p.size, 0) AS size,
+			       wp.total_count, wp.left_count,
+			       (SELECT COALESCE(SUM(re.count), 0) FROM reservation re
+			        WHERE re.warehouse_id = wp.warehouse_id AND re.product_code = wp.product_code
+			          AND re.status IN ($2, $3)) AS reserved
+			FROM warehouse_product wp
+			LEFT JOIN product p ON p.unique_code = wp.product_code
+			WHERE wp.warehouse_id = $1
+		) AS stock
+		WHERE ($4 = '' OR product_code LIKE $4 || '%')
+		  AND ($5 = '' OR name ILIKE '%' || $5 || '%')
+		  AND (NOT $6 OR left_count > 0)
+		  ` + after + `
+		ORDER BY ` + column + ` ` + order + `, product_code ` + order + `
+		LIMIT $7;`
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]model.ProductStock, 0)
+	for rows.Next() {
+		p := model.ProductStock{}
+		err = rows.Scan(&p.ProductCode, &p.Name, &p.Size, &p.TotalCount, &p.LeftCount, &p.Reserved)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// productStockSortColumns maps the sort orders of AllProducts to the columns they sort by.
+var productStockSortColumns = map[string]string{
+	model.SortByCode:       "product_code",
+	model.SortByName:       "name",
+	model.SortByTotalCount: "total_count",
+	model.SortByLeftCount:  "left_count",
+	model.SortByReserved:   "reserved",
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern so that it matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ReceiveProduct adds the received count to total_count and left_count of the product in the
+// warehouse, creating its warehouse_product row if needed, adds it to the lot of the receipt if
+// any, registers the units of a serialized product and records the movement. The received
+// stock is first offered to waiting backorders. The receipt fails with ErrCapacityExceeded if
+// the warehouse cannot hold it.
+func (r *warehouseRepository) ReceiveProduct(receipt *model.Receipt) (*model.WarehouseProduct, *model.Lot, error) {
+	r.Logger.Info("start repository ReceiveProduct")
+
+	if receipt.Count <= 0 {
+		return nil, nil, ErrInvalidCount
+	}
+
+	var item *model.WarehouseProduct
+	var lot *model.Lot
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `SELECT unique_code, serialized FROM product WHERE unique_code = $1 AND deleted_at IS NULL FOR SHARE;`
+		serialized := false
+		err := tx.QueryRow(query, receipt.ProductCode).Scan(&receipt.ProductCode, &serialized)
+		if err != nil {
+			return err
+		}
+		if err = checkSerials(receipt, serialized); err != nil {
+			return err
+		}
+
+		item, err = addStock(tx, receipt.WarehouseID, receipt.ProductCode, receipt.Count)
+		if err != nil {
+			return err
+		}
+		if err = checkCapacity(tx, receipt.WarehouseID); err != nil {
+			return err
+		}
+		if receipt.LotNumber != "" {
+			lot, err = addLot(tx, receipt.WarehouseID, receipt.ProductCode, receipt.LotNumber, receipt.ExpiresOn,
+				receipt.Count)
+			if err != nil {
+				return err
+			}
+		}
+		if err = registerSerials(tx, receipt); err != nil {
+			return err
+		}
+
+		err = insertMovement(tx, &model.StockMovement{
+			WarehouseID: receipt.WarehouseID,
+			ProductCode: receipt.ProductCode,
+			Delta:       receipt.Count,
+			TotalDelta:  receipt.Count,
+			Reason:      model.MovementReceive,
+			UserID:      receipt.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		allocated, err := allocateBackorders(tx, receipt.WarehouseID, receipt.ProductCode)
+		if err != nil {
+			return err
+		}
+		item.LeftCount -= allocated
+		if lot != nil {
+			query = `SELECT ` + lotColumns + ` FROM lot WHERE id = $1;`
+			lot, err = scanLot(tx.QueryRow(query, lot.ID))
+		}
+		return err
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, nil, err
+	}
+	return item, lot, nil
+}
+
+// addStock adds count to total_count and left_count of the product in the warehouse, creating
+// its warehouse_product row if needed, and returns the updated row.
+func addStock(tx *sql.Tx, warehouseID int, productCode string, count int) (*model.WarehouseProduct, error) {
+	item := &model.WarehouseProduct{WarehouseID: warehouseID, ProductCode: productCode}
+	query := `INSERT INTO warehouse_product (warehouse_id, product_code, total_count, left_count) VALUES ($1, $2, $3, $3)
+		ON CONFLICT (warehouse_id, product_code) DO UPDATE
+		SET total_count = warehouse_product.total_count + EXCLUDED.total_count,
+		    left_count  = warehouse_product.left_count + EXCLUDED.left_count
+		RETURNING id, total_count, left_count, reorder_threshold;`
+	err := tx.QueryRow(query, warehouseID, productCode, count).
+		Scan(&item.ID, &item.TotalCount, &item.LeftCount, &item.ReorderThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+const warehouseProductColumns = `id, warehouse_id, product_code, total_count, left_count, reorder_threshold`
+
+func scanWarehouseProduct(row scanner) (*model.WarehouseProduct, error) {
+	item := &model.WarehouseProduct{}
+	err := row.Scan(&item.ID, &item.WarehouseID, &item.ProductCode, &item.TotalCount, &item.LeftCount,
+		&item.ReorderThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *warehouseRepository) GetStock(warehouseID int, productCode string) (*model.WarehouseProduct, error) {
+	r.Logger.Info("start repository GetStock")
+
+	query := `SELECT ` + warehouseProductColumns + ` FROM warehouse_product WHERE warehouse_id = $1 AND product_code = $2;`
+	item, err := scanWarehouseProduct(r.DB.QueryRow(query, warehouseID, productCode))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *warehouseRepository) SetReorderThreshold(warehouseID int, productCode string, threshold int) (*model.WarehouseProduct, error) {
+	r.Logger.Info("start repository SetReorderThreshold")
+
+	query := `UPDATE warehouse_product SET reorder_threshold = $1 WHERE warehouse_id = $2 AND product_code = $3
+		RETURNING ` + warehouseProductColumns + `;`
+	item, err := scanWarehouseProduct(r.DB.QueryRow(query, threshold, warehouseID, productCode))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return item, nil
+}
+
+// LowStock returns every product whose free stock is below its reorder threshold, across all warehouses.
+func (r *warehouseRepository) LowStock() ([]model.WarehouseProduct, error) {
+	r.Logger.Info("start repository LowStock")
+
+	query := `SELECT ` + warehouseProductColumns + ` FROM warehouse_product WHERE left_count < reorder_threshold
+		ORDER BY warehouse_id, product_code;`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.WarehouseProduct, 0)
+	for rows.Next() {
+		item, err := scanWarehouseProduct(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// CreateWarehouse adds the warehouse and records its initial availability as made by userID.
+func (r *warehouseRepository) CreateWarehouse(warehouse *model.Warehouse, userID string) error {
+	r.Logger.Info("start repository CreateWarehouse")
+
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `INSERT INTO warehouse (name, available, capacity) VALUES ($1, $2, $3)
+			ON CONFLICT (name) DO NOTHING RETURNING id;`
+		err := tx.QueryRow(query, warehouse.Name, warehouse.Availability, warehouse.Capacity).Scan(&warehouse.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWarehouseExists
+		}
+		if err != nil {
+			return err
+		}
+
+		return insertAvailabilityChange(tx, &model.AvailabilityChange{
+			WarehouseID: warehouse.ID,
+			Available:   warehouse.Availability,
+			Reason:      "created",
+			UserID:      userID,
+		})
+	})
+	if err != nil {
+		r.Logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// UpdateWarehouse changes the name of the warehouse unless name is empty and its capacity
+// unless capacity is nil. Names are unique: it returns ErrWarehouseExists if another warehouse
+// has the name.
+func (r *warehouseRepository) UpdateWarehouse(warehouseID int, name string, capacity *int) (*model.Warehouse, error) {
+	r.Logger.Info("start repository UpdateWarehouse")
+
+	query := `UPDATE warehouse SET name = COALESCE(NULLIF($1, ''), name), capacity = COALESCE($2, capacity)
+		WHERE id = $3 RETURNING ` + warehouseColumns + `;`
+	warehouse, err := scanWarehouse(r.DB.QueryRow(query, name, capacity, warehouseID))
+	if isUniqueViolation(err) {
+		err = ErrWarehouseExists
+	}
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (r *warehouseRepository) GetWarehouse(warehouseID int) (*model.Warehouse, error) {
+	r.Logger.Info("start repository GetWarehouse")
+
+	query := `SELECT ` + warehouseColumns + ` FROM warehouse WHERE id = $1;`
+	warehouse, err := scanWarehouse(r.DB.QueryRow(query, warehouseID))
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// ListWarehouses returns all warehouses ordered by ID.
+func (r *warehouseRepository) ListWarehouses() ([]model.Warehouse, error) {
+	r.Logger.Info("start repository ListWarehouses")
+
+	query := `SELECT ` + warehouseColumns + ` FROM warehouse ORDER BY id;`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
 	defer rows.Close()
 
+	warehouses := make([]model.Warehouse, 0)
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		warehouses = append(warehouses, *warehouse)
+	}
+	return warehouses, rows.Err()
+}
+
+// SetAvailability makes the warehouse available or unavailable and records the change in the
+// availability log in the same transaction.
+func (r *warehouseRepository) SetAvailability(change *model.AvailabilityChange) (*model.Warehouse, error) {
+	r.Logger.Info("start repository SetAvailability")
+
+	var warehouse *model.Warehouse
+	err := withTx(r.DB, func(tx *sql.Tx) error {
+		query := `UPDATE warehouse SET available = $1 WHERE id = $2 RETURNING ` + warehouseColumns + `;`
+		var err error
+		warehouse, err = scanWarehouse(tx.QueryRow(query, change.Available, change.WarehouseID))
+		if err != nil {
+			return err
+		}
+		return insertAvailabilityChange(tx, change)
+	})
 	if err != nil {
 		r.Logger.Error(err)
 		return nil, err
 	}
+	return warehouse, nil
+}
+
+// AvailabilityHistory returns the availability changes of the warehouse, latest first.
+func (r *warehouseRepository) AvailabilityHistory(warehouseID int) ([]model.AvailabilityChange, error) {
+	r.Logger.Info("start repository AvailabilityHistory")
+
+	query := `SELECT id, warehouse_id, available, reason, user_id, created_at FROM warehouse_availability_log
+		WHERE warehouse_id = $1 ORDER BY id DESC;`
+	rows, err := r.DB.Query(query, warehouseID)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
 
+	changes := make([]model.AvailabilityChange, 0)
 	for rows.Next() {
-		var code string
-		err = rows.Scan(&code)
+		change := model.AvailabilityChange{}
+		userID := sql.NullString{}
+		err = rows.Scan(&change.ID, &change.WarehouseID, &change.Available, &change.Reason, &userID, &change.CreatedAt)
 		if err != nil {
 			r.Logger.Error(err)
 			return nil, err
 		}
-		codes = append(codes, code)
+		change.UserID = userID.String
+		changes = append(changes, change)
 	}
+	return changes, rows.Err()
+}
+
+func insertAvailabilityChange(tx *sql.Tx, change *model.AvailabilityChange) error {
+	query := `INSERT INTO warehouse_availability_log (warehouse_id, available, reason, user_id)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at;`
+	return tx.QueryRow(query, change.WarehouseID, change.Available, change.Reason, nullString(change.UserID)).
+		Scan(&change.ID, &change.CreatedAt)
+}
+
+const warehouseColumns = `id, name, available, capacity`
+
+func scanWarehouse(row scanner) (*model.Warehouse, error) {
+	warehouse := &model.Warehouse{}
+	err := row.Scan(&warehouse.ID, &warehouse.Name, &warehouse.Availability, &warehouse.Capacity)
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// Utilization returns the capacity, the occupied volume and the volume in transit to every
+// warehouse ordered by ID.
+func (r *warehouseRepository) Utilization() ([]model.Utilization, error) {
+	r.Logger.Info("start repository Utilization")
+
+	query := `SELECT w.id, w.name, w.capacity, COALESCE(SUM(wp.total_count * COALESCE(p.size, 0)), 0),
+			(` + inTransitVolume("w.id", "$1") + `)
+		FROM warehouse w
+		LEFT JOIN warehouse_product wp ON wp.warehouse_id = w.id
+		LEFT JOIN product p ON p.unique_code = wp.product_code
+		GROUP BY w.id
+		ORDER BY w.id;`
+	rows, err := r.DB.Query(query, model.TransferInTransit)
+	if err != nil {
+		r.Logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	utilization := make([]model.Utilization, 0)
+	for rows.Next() {
+		u := model.Utilization{}
+		if err = rows.Scan(&u.WarehouseID, &u.Name, &u.Capacity, &u.Occupied, &u.InTransit); err != nil {
+			r.Logger.Error(err)
+			return nil, err
+		}
+		utilization = append(utilization, u)
+	}
+	return utilization, rows.Err()
+}
+
+// checkCapacity fails with ErrCapacityExceeded if the products stocked in the warehouse and
+// those in transit to it take more volume than the warehouse capacity. Stock already added in
+// the transaction is counted, so it is called after adding to check the result. Holding the
+// volume of in-transit transfers means that receiving them never exceeds the capacity. The
+// warehouse row is locked for the rest of the transaction, which serializes concurrent
+// additions to the same warehouse.
+func checkCapacity(tx *sql.Tx, warehouseID int) error {
+	query := `SELECT capacity FROM warehouse WHERE id = $1 FOR UPDATE;`
+	capacity := 0
+	err := tx.QueryRow(query, warehouseID).Scan(&capacity)
+	if err != nil {
+		return err
+	}
+	if capacity == 0 {
+		return nil
+	}
+
+	query = `SELECT COALESCE(SUM(wp.total_count * COALESCE(p.size, 0)), 0) + (` + inTransitVolume("$1", "$2") + `)
+		FROM warehouse_product wp
+		JOIN product p ON p.unique_code = wp.product_code
+		WHERE wp.warehouse_id = $1;`
+	occupied := 0
+	err = tx.QueryRow(query, warehouseID, model.TransferInTransit).Scan(&occupied)
+	if err != nil {
+		return err
+	}
+	if occupied > capacity {
+		return ErrCapacityExceeded
+	}
+	return nil
+}
 
-	return codes, nil
+// inTransitVolume returns a subquery selecting the volume of the transfers to warehouse in
+// status, both SQL expressions, so that the capacity check and the utilization report count
+// in-transit stock the same way.
+func inTransitVolume(warehouse string, status string) string {
+	return `SELECT COALESCE(SUM(t.count * COALESCE(tp.size, 0)), 0)
+		FROM stock_transfer t
+		JOIN product tp ON tp.unique_code = t.product_code
+		WHERE t.to_warehouse_id = ` + warehouse + ` AND t.status = ` + status
 }
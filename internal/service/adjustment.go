@@ -0,0 +1,151 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	"slices"
+)
+
+var ErrInvalidDelta = errors.New("invalid delta")
+var ErrInvalidAdjustmentReason = errors.New("invalid adjustment reason")
+var ErrNonExistAdjustmentId = errors.New("non-existent adjustment id")
+var ErrAdjustmentNotPending = errors.New("adjustment is not pending")
+
+// AdjustStock corrects the stock of a product in a warehouse by a signed delta, for a reason
+// from the configured set. An adjustment by a warehouse worker changing the stock by more than
+// the configured approval limit is only recorded as pending until an admin approves it; other
+// adjustments are applied right away.
+func (s *warehouseService) AdjustStock(req *DTO.ReqAdjustStock, user *AuthInfo) (*DTO.Adjustment, error) {
+	s.Logger.Info("start service AdjustStock")
+
+	if req.Delta == 0 {
+		return nil, ErrInvalidDelta
+	}
+	if !slices.Contains(s.Config.Adjustment.Reasons, req.Reason) {
+		return nil, ErrInvalidAdjustmentReason
+	}
+	if _, err := s.Repository.GetWarehouse(req.WarehouseID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistWarehouse
+		}
+		return nil, ErrInternal
+	}
+
+	adjustment := &model.Adjustment{
+		WarehouseID:   req.WarehouseID,
+		ProductCode:   req.UniqueCode,
+		Delta:         req.Delta,
+		Reason:        req.Reason,
+		Note:          req.Note,
+		SerialNumbers: req.SerialNumbers,
+		Status:        model.AdjustmentApplied,
+		CreatedBy:     user.ID,
+	}
+	if user.Role != admin && s.needsApproval(req.Delta) {
+		adjustment.Status = model.AdjustmentPending
+	}
+	if err := s.Repository.CreateAdjustment(adjustment); err != nil {
+		return nil, lineError(err)
+	}
+	s.adjusted(adjustment)
+
+	res := toAdjustmentDTO(adjustment)
+	return &res, nil
+}
+
+// ApproveAdjustment applies a pending adjustment.
+func (s *warehouseService) ApproveAdjustment(req *DTO.ReqReviewAdjustment, user *AuthInfo) (*DTO.Adjustment, error) {
+	s.Logger.Info("start service ApproveAdjustment")
+	return s.reviewAdjustment(req.ID, model.AdjustmentApplied, user)
+}
+
+// RejectAdjustment discards a pending adjustment without changing the stock.
+func (s *warehouseService) RejectAdjustment(req *DTO.ReqReviewAdjustment, user *AuthInfo) (*DTO.Adjustment, error) {
+	s.Logger.Info("start service RejectAdjustment")
+	return s.reviewAdjustment(req.ID, model.AdjustmentRejected, user)
+}
+
+func (s *warehouseService) reviewAdjustment(adjustmentID int, status string, user *AuthInfo) (*DTO.Adjustment, error) {
+	adjustment, err := s.Repository.ReviewAdjustment(adjustmentID, status, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNonExistAdjustmentId
+		case errors.Is(err, repository.ErrAdjustmentNotPending):
+			return nil, ErrAdjustmentNotPending
+		default:
+			return nil, lineError(err)
+		}
+	}
+	s.adjusted(adjustment)
+
+	res := toAdjustmentDTO(adjustment)
+	return &res, nil
+}
+
+// ListAdjustments lists the adjustments matching the request in the order they were made.
+func (s *warehouseService) ListAdjustments(req *DTO.ReqListAdjustments) (*DTO.ResListAdjustments, error) {
+	s.Logger.Info("start service ListAdjustments")
+
+	if req.Status != "" && !model.IsAdjustmentStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	limit, err := pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments, err := s.Repository.ListAdjustments(&model.AdjustmentFilter{
+		WarehouseID: req.WarehouseID,
+		ProductCode: req.UniqueCode,
+		Status:      req.Status,
+		Cursor:      req.Cursor,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResListAdjustments{Adjustments: make([]DTO.Adjustment, 0, len(adjustments))}
+	for i := range adjustments {
+		res.Adjustments = append(res.Adjustments, toAdjustmentDTO(&adjustments[i]))
+	}
+	if len(adjustments) == limit {
+		res.NextCursor = adjustments[len(adjustments)-1].ID
+	}
+	return res, nil
+}
+
+// needsApproval reports whether an adjustment by delta exceeds the approval limit.
+func (s *warehouseService) needsApproval(delta int) bool {
+	limit := s.Config.Adjustment.ApprovalLimit
+	return limit > 0 && (delta > limit || delta < -limit)
+}
+
+// adjusted emits a low-stock event if an applied write-off took the stock below its threshold.
+func (s *warehouseService) adjusted(adjustment *model.Adjustment) {
+	if adjustment.Status == model.AdjustmentApplied && adjustment.Delta < 0 {
+		s.Alerts.dropped(adjustment.WarehouseID, adjustment.ProductCode, -adjustment.Delta)
+	}
+}
+
+func toAdjustmentDTO(adjustment *model.Adjustment) DTO.Adjustment {
+	return DTO.Adjustment{
+		ID:            adjustment.ID,
+		WarehouseID:   adjustment.WarehouseID,
+		UniqueCode:    adjustment.ProductCode,
+		Delta:         adjustment.Delta,
+		Reason:        adjustment.Reason,
+		Note:          adjustment.Note,
+		SerialNumbers: adjustment.SerialNumbers,
+		Status:        adjustment.Status,
+		CreatedBy:     adjustment.CreatedBy,
+		ReviewedBy:    adjustment.ReviewedBy,
+		CreatedAt:     adjustment.CreatedAt,
+		ReviewedAt:    adjustment.ReviewedAt,
+		Lots:          toLotCountDTOs(adjustment.Lots),
+	}
+}
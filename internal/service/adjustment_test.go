@@ -0,0 +1,251 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestNeedsApproval(t *testing.T) {
+	testTable := []struct {
+		name          string
+		approvalLimit int
+		delta         int
+		expected      bool
+	}{
+		{
+			name:          "No approval limit",
+			approvalLimit: 0,
+			delta:         -1000,
+			expected:      false,
+		},
+		{
+			name:          "Within the limit",
+			approvalLimit: 50,
+			delta:         50,
+			expected:      false,
+		},
+		{
+			name:          "Addition above the limit",
+			approvalLimit: 50,
+			delta:         51,
+			expected:      true,
+		},
+		{
+			name:          "Write-off above the limit",
+			approvalLimit: 50,
+			delta:         -51,
+			expected:      true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			s := &warehouseService{Config: config.Config{Adjustment: config.AdjustmentConfig{
+				ApprovalLimit: test.approvalLimit,
+			}}}
+			assert.Equal(t, test.expected, s.needsApproval(test.delta))
+		})
+	}
+}
+
+func TestWarehouseService_AdjustStock(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	created := func(delta int, status string, createdBy string) *model.Adjustment {
+		return &model.Adjustment{WarehouseID: 1, ProductCode: "olkiuj", Delta: delta, Reason: "damaged",
+			Status: status, CreatedBy: createdBy}
+	}
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqAdjustStock
+		user          AuthInfo
+		mockBehaviour mockBehaviour
+		expected      *DTO.Adjustment
+		expectedErr   error
+	}{
+		{
+			name: "Within the limit - applied",
+			req:  DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -50, Reason: "damaged"},
+			user: AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetWarehouse(1).Return(&model.Warehouse{ID: 1}, nil)
+				r.EXPECT().CreateAdjustment(created(-50, model.AdjustmentApplied, "lol")).Return(nil)
+				r.EXPECT().GetStock(1, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.Adjustment{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -50, Reason: "damaged",
+				Status: model.AdjustmentApplied, CreatedBy: "lol"},
+		},
+		{
+			name: "Above the limit - pending",
+			req:  DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -51, Reason: "damaged"},
+			user: AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetWarehouse(1).Return(&model.Warehouse{ID: 1}, nil)
+				r.EXPECT().CreateAdjustment(created(-51, model.AdjustmentPending, "lol")).Return(nil)
+			},
+			expected: &DTO.Adjustment{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -51, Reason: "damaged",
+				Status: model.AdjustmentPending, CreatedBy: "lol"},
+		},
+		{
+			name: "Above the limit by an admin - applied",
+			req:  DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: 51, Reason: "damaged"},
+			user: AuthInfo{ID: "root", Role: admin},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetWarehouse(1).Return(&model.Warehouse{ID: 1}, nil)
+				r.EXPECT().CreateAdjustment(created(51, model.AdjustmentApplied, "root")).Return(nil)
+			},
+			expected: &DTO.Adjustment{WarehouseID: 1, UniqueCode: "olkiuj", Delta: 51, Reason: "damaged",
+				Status: model.AdjustmentApplied, CreatedBy: "root"},
+		},
+		{
+			name: "Not enough stock to write off",
+			req:  DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -5, Reason: "damaged"},
+			user: AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetWarehouse(1).Return(&model.Warehouse{ID: 1}, nil)
+				r.EXPECT().CreateAdjustment(gomock.Any()).Return(repository.ErrNotEnoughLeft)
+			},
+			expectedErr: ErrNotEnoughProduct,
+		},
+		{
+			name: "Non-existent warehouse",
+			req:  DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -5, Reason: "damaged"},
+			user: AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetWarehouse(1).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistWarehouse,
+		},
+		{
+			name:          "Zero delta",
+			req:           DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: 0, Reason: "damaged"},
+			user:          AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidDelta,
+		},
+		{
+			name:          "Unknown reason",
+			req:           DTO.ReqAdjustStock{WarehouseID: 1, UniqueCode: "olkiuj", Delta: -2, Reason: "stolen"},
+			user:          AuthInfo{ID: "lol", Role: warehouseWorker},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidAdjustmentReason,
+		},
+	}
+
+	c := config.Config{Adjustment: config.AdjustmentConfig{
+		Reasons:       []string{"damaged", "lost", "found", "cycle-count"},
+		ApprovalLimit: 50,
+	}}
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, c, notifier.NewLogNotifier())
+			res, err := s.AdjustStock(&test.req, &test.user)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_ReviewAdjustment(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	reviewed := func(status string) *model.Adjustment {
+		return &model.Adjustment{ID: 7, WarehouseID: 1, ProductCode: "olkiuj", Delta: -80, Reason: "lost",
+			Status: status, CreatedBy: "lol", ReviewedBy: "root"}
+	}
+
+	testTable := []struct {
+		name          string
+		approve       bool
+		mockBehaviour mockBehaviour
+		expected      *DTO.Adjustment
+		expectedErr   error
+	}{
+		{
+			name:    "Approved",
+			approve: true,
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentApplied, "root").Return(reviewed(model.AdjustmentApplied), nil)
+				r.EXPECT().GetStock(1, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.Adjustment{ID: 7, WarehouseID: 1, UniqueCode: "olkiuj", Delta: -80, Reason: "lost",
+				Status: model.AdjustmentApplied, CreatedBy: "lol", ReviewedBy: "root"},
+		},
+		{
+			name: "Rejected",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentRejected, "root").Return(reviewed(model.AdjustmentRejected), nil)
+			},
+			expected: &DTO.Adjustment{ID: 7, WarehouseID: 1, UniqueCode: "olkiuj", Delta: -80, Reason: "lost",
+				Status: model.AdjustmentRejected, CreatedBy: "lol", ReviewedBy: "root"},
+		},
+		{
+			name:    "Non-existent adjustment",
+			approve: true,
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentApplied, "root").Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistAdjustmentId,
+		},
+		{
+			name: "Already reviewed",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentRejected, "root").Return(nil, repository.ErrAdjustmentNotPending)
+			},
+			expectedErr: ErrAdjustmentNotPending,
+		},
+		{
+			name:    "Stock went below the write-off",
+			approve: true,
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentApplied, "root").Return(nil, repository.ErrNotEnoughLeft)
+			},
+			expectedErr: ErrNotEnoughProduct,
+		},
+		{
+			name:    "Repository error",
+			approve: true,
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReviewAdjustment(7, model.AdjustmentApplied, "root").Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			user := &AuthInfo{ID: "root", Role: admin}
+			req := &DTO.ReqReviewAdjustment{ID: 7}
+			review := s.RejectAdjustment
+			if test.approve {
+				review = s.ApproveAdjustment
+			}
+			res, err := review(req, user)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
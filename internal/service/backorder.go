@@ -0,0 +1,121 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+)
+
+var ErrNonExistBackorderId = errors.New("non-existent backorder id")
+var ErrBackorderNotWaiting = errors.New("backorder is not waiting")
+
+// backorder reserves the stock that is left for a reservation line that failed for lack of
+// stock and queues the shortfall. The queued part is reserved with the given TTL once enough
+// stock is received or freed. It returns nil if nothing had to be queued. The count of re is set
+// to the count reserved now and its ID is zero if nothing was.
+func (s *productService) backorder(re *model.Reservation, ttl int) (*DTO.Backorder, error) {
+	backorder := &model.Backorder{
+		WarehouseID: re.WarehouseID,
+		ProductCode: re.ProductCode,
+		TTL:         s.ttl(ttl),
+		UserID:      re.UserID,
+	}
+	err := s.ProductRepository.Backorder(re, backorder)
+	if err != nil {
+		return nil, err
+	}
+	if backorder.ID == 0 {
+		return nil, nil
+	}
+
+	res := toBackorderDTO(backorder)
+	return &res, nil
+}
+
+// CancelBackorder takes a waiting backorder out of the queue. Only the user who made it or an
+// admin may cancel it.
+func (s *productService) CancelBackorder(req *DTO.ReqCancelBackorder, user *AuthInfo) (*DTO.Backorder, error) {
+	s.Logger.Info("start service CancelBackorder")
+
+	backorder, err := s.ProductRepository.GetBackorder(req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistBackorderId
+		}
+		return nil, ErrInternal
+	}
+
+	if user.Role != admin && backorder.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+
+	backorder, err = s.ProductRepository.CancelBackorder(req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNonExistBackorderId
+		case errors.Is(err, repository.ErrBackorderNotWaiting):
+			return nil, ErrBackorderNotWaiting
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	res := toBackorderDTO(backorder)
+	return &res, nil
+}
+
+// ListBackorders lists backorders matching the request in queue order. Users other than admins
+// only see their own backorders.
+func (s *productService) ListBackorders(req *DTO.ReqListBackorders, user *AuthInfo) (*DTO.ResListBackorders, error) {
+	s.Logger.Info("start service ListBackorders")
+
+	if req.Status != "" && !model.IsBackorderStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	limit, err := pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := req.Owner
+	if user.Role != admin {
+		owner = user.ID
+	}
+
+	backorders, err := s.ProductRepository.ListBackorders(&model.BackorderFilter{
+		WarehouseID: req.WarehouseID,
+		ProductCode: req.UniqueCode,
+		UserID:      owner,
+		Status:      req.Status,
+		Cursor:      req.Cursor,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResListBackorders{Backorders: make([]DTO.Backorder, 0, len(backorders))}
+	for i := range backorders {
+		res.Backorders = append(res.Backorders, toBackorderDTO(&backorders[i]))
+	}
+	if len(backorders) == limit {
+		res.NextCursor = backorders[len(backorders)-1].ID
+	}
+	return res, nil
+}
+
+func toBackorderDTO(backorder *model.Backorder) DTO.Backorder {
+	return DTO.Backorder{
+		ID:            backorder.ID,
+		WarehouseID:   backorder.WarehouseID,
+		UniqueCode:    backorder.ProductCode,
+		Count:         backorder.Count,
+		Status:        backorder.Status,
+		Owner:         backorder.UserID,
+		ReservationID: backorder.ReservationID,
+		CreatedAt:     backorder.CreatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestProductService_Reserve_Backorder(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository)
+
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	req := &DTO.ReqReserveProduct{
+		WarehouseID: 2,
+		UniqueCodes: []string{"olkiuj"},
+		Counts:      []int{1200},
+		Backorder:   true,
+	}
+	// backorder mimics the repository: left is the free stock when the line is backordered.
+	backorder := func(left int) func(re *model.Reservation, backorder *model.Backorder) error {
+		return func(re *model.Reservation, backorder *model.Backorder) error {
+			backorder.Count = re.Count - left
+			if left > 0 {
+				re.Count = min(re.Count, left)
+				re.ID = 9
+			}
+			if backorder.Count > 0 {
+				backorder.ID = 3
+				backorder.Status = model.BackorderWaiting
+				backorder.CreatedAt = createdAt
+			}
+			return nil
+		}
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResReserveProduct
+	}{
+		{
+			name: "Shortfall backordered, the rest reserved",
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ReserveProduct(gomock.Any()).Return(nil, repository.ErrNotEnoughLeft)
+				p.EXPECT().Backorder(gomock.Any(), gomock.Any()).DoAndReturn(backorder(1000))
+				w.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful: []DTO.Successful{{ID: 9, UniqueCode: "olkiuj", Count: 1000}},
+				Backordered: []DTO.Backorder{{
+					ID: 3, WarehouseID: 2, UniqueCode: "olkiuj", Count: 200, Status: model.BackorderWaiting,
+					Owner: "lol", CreatedAt: createdAt,
+				}},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "No stock left",
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ReserveProduct(gomock.Any()).Return(nil, repository.ErrNotEnoughLeft)
+				p.EXPECT().Backorder(gomock.Any(), gomock.Any()).DoAndReturn(backorder(0))
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful: []DTO.Successful{},
+				Backordered: []DTO.Backorder{{
+					ID: 3, WarehouseID: 2, UniqueCode: "olkiuj", Count: 1200, Status: model.BackorderWaiting,
+					Owner: "lol", CreatedAt: createdAt,
+				}},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Stock freed before backordering",
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ReserveProduct(gomock.Any()).Return(nil, repository.ErrNotEnoughLeft)
+				p.EXPECT().Backorder(gomock.Any(), gomock.Any()).DoAndReturn(backorder(1500))
+				w.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful:   []DTO.Successful{{ID: 9, UniqueCode: "olkiuj"}},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Backorder failed",
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ReserveProduct(gomock.Any()).Return(nil, repository.ErrNotEnoughLeft)
+				p.EXPECT().Backorder(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful:   []DTO.Successful{},
+				Unsuccessful: []string{"olkiuj"},
+				Errors:       []string{ErrInternal.Error()},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			warehouseRepository := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*productRepository, *warehouseRepository)
+
+			s := NewProductService(productRepository, warehouseRepository, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Reserve(req, &AuthInfo{ID: "lol", Role: productWorker})
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	"example1/pkg/logger"
+)
+
+var ErrInvalidProduct = errors.New("invalid product")
+var ErrProductExists = errors.New("product with this unique code already exists")
+var ErrNonExistProduct = errors.New("non-existent product")
+var ErrProductInStock = errors.New("product is in stock")
+var ErrProductReserved = errors.New("product has live reservations or backorders")
+
+type catalogService struct {
+	Repository repository.CatalogRepository
+	Logger     logger.Logger
+}
+
+func NewCatalogService(r repository.CatalogRepository) CatalogService {
+	return &catalogService{r, logger.Get()}
+}
+
+//go:generate mockgen -source=catalog.go -destination=mocks/mock_catalog.go
+
+type CatalogService interface {
+	CreateProduct(req *DTO.ReqProduct) (*DTO.Product, error)
+	UpdateProduct(req *DTO.ReqProduct) (*DTO.Product, error)
+	GetProduct(req *DTO.ReqGetProduct) (*DTO.Product, error)
+	ListProducts(req *DTO.ReqListProducts) (*DTO.ResListProducts, error)
+	DeleteProduct(req *DTO.ReqGetProduct) (*DTO.Product, error)
+}
+
+func (s *catalogService) CreateProduct(req *DTO.ReqProduct) (*DTO.Product, error) {
+	s.Logger.Info("start service CreateProduct")
+
+	product, err := toProduct(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.CreateProduct(product)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductExists
+		}
+		return nil, ErrInternal
+	}
+
+	res := toProductDTO(product)
+	return &res, nil
+}
+
+// UpdateProduct changes the name, size and serialized flag of the product with the requested
+// unique code. Deleted products cannot be updated, and the flag of a product in stock cannot be
+// changed.
+func (s *catalogService) UpdateProduct(req *DTO.ReqProduct) (*DTO.Product, error) {
+	s.Logger.Info("start service UpdateProduct")
+
+	product, err := toProduct(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.UpdateProduct(product)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNonExistProduct
+		case errors.Is(err, repository.ErrProductInStock):
+			return nil, ErrProductInStock
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	res := toProductDTO(product)
+	return &res, nil
+}
+
+func (s *catalogService) GetProduct(req *DTO.ReqGetProduct) (*DTO.Product, error) {
+	s.Logger.Info("start service GetProduct")
+
+	product, err := s.Repository.GetProduct(req.UniqueCode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistProduct
+		}
+		return nil, ErrInternal
+	}
+
+	res := toProductDTO(product)
+	return &res, nil
+}
+
+// ListProducts lists catalog products in ID order. NextCursor is set when there may be more of
+// them and is passed as Cursor to get the next page.
+func (s *catalogService) ListProducts(req *DTO.ReqListProducts) (*DTO.ResListProducts, error) {
+	s.Logger.Info("start service ListProducts")
+
+	limit, err := pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.Repository.ListProducts(&model.ProductFilter{
+		Name:        req.Name,
+		WithDeleted: req.WithDeleted,
+		Cursor:      req.Cursor,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResListProducts{Products: make([]DTO.Product, 0, len(products))}
+	for i := range products {
+		res.Products = append(res.Products, toProductDTO(&products[i]))
+	}
+	if len(products) == limit {
+		res.NextCursor = products[len(products)-1].ID
+	}
+	return res, nil
+}
+
+// DeleteProduct soft-deletes the product. Products that are still in stock in any warehouse or
+// have live reservations or waiting backorders cannot be deleted.
+func (s *catalogService) DeleteProduct(req *DTO.ReqGetProduct) (*DTO.Product, error) {
+	s.Logger.Info("start service DeleteProduct")
+
+	product, err := s.Repository.DeleteProduct(req.UniqueCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, repository.ErrProductDeleted):
+			return nil, ErrNonExistProduct
+		case errors.Is(err, repository.ErrProductInStock):
+			return nil, ErrProductInStock
+		case errors.Is(err, repository.ErrProductReserved):
+			return nil, ErrProductReserved
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	res := toProductDTO(product)
+	return &res, nil
+}
+
+func toProduct(req *DTO.ReqProduct) (*model.Product, error) {
+	if req.UniqueCode == "" || req.Name == "" || req.Size <= 0 {
+		return nil, ErrInvalidProduct
+	}
+	return &model.Product{UniqueCode: req.UniqueCode, Name: req.Name, Size: req.Size, Serialized: req.Serialized}, nil
+}
+
+func toProductDTO(product *model.Product) DTO.Product {
+	return DTO.Product{
+		ID:         product.ID,
+		UniqueCode: product.UniqueCode,
+		Name:       product.Name,
+		Size:       product.Size,
+		Serialized: product.Serialized,
+		DeletedAt:  product.DeletedAt,
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestCatalogService_CreateProduct(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockCatalogRepository)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqProduct
+		mockBehaviour mockBehaviour
+		expected      *DTO.Product
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "chair", Size: 3, Serialized: true},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().CreateProduct(&model.Product{UniqueCode: "olkiuj", Name: "chair", Size: 3, Serialized: true}).
+					DoAndReturn(func(product *model.Product) error {
+						product.ID = 1
+						return nil
+					})
+			},
+			expected: &DTO.Product{ID: 1, UniqueCode: "olkiuj", Name: "chair", Size: 3, Serialized: true},
+		},
+		{
+			name:          "Without name",
+			req:           DTO.ReqProduct{UniqueCode: "olkiuj", Size: 3},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {},
+			expectedErr:   ErrInvalidProduct,
+		},
+		{
+			name:          "Without unique code",
+			req:           DTO.ReqProduct{Name: "chair", Size: 3},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {},
+			expectedErr:   ErrInvalidProduct,
+		},
+		{
+			name:          "Zero size",
+			req:           DTO.ReqProduct{UniqueCode: "olkiuj", Name: "chair"},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {},
+			expectedErr:   ErrInvalidProduct,
+		},
+		{
+			name: "Duplicate unique code",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "table", Size: 5},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().CreateProduct(gomock.Any()).Return(sql.ErrNoRows)
+			},
+			expectedErr: ErrProductExists,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "table", Size: 5},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().CreateProduct(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockCatalogRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			res, err := NewCatalogService(r).CreateProduct(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestCatalogService_UpdateProduct(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockCatalogRepository)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqProduct
+		mockBehaviour mockBehaviour
+		expected      *DTO.Product
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "armchair", Size: 4},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().UpdateProduct(&model.Product{UniqueCode: "olkiuj", Name: "armchair", Size: 4}).
+					DoAndReturn(func(product *model.Product) error {
+						product.ID = 1
+						return nil
+					})
+			},
+			expected: &DTO.Product{ID: 1, UniqueCode: "olkiuj", Name: "armchair", Size: 4},
+		},
+		{
+			name:          "Invalid product",
+			req:           DTO.ReqProduct{UniqueCode: "olkiuj", Name: "armchair", Size: -1},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {},
+			expectedErr:   ErrInvalidProduct,
+		},
+		{
+			name: "Non-existent or deleted product",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "armchair", Size: 4},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().UpdateProduct(gomock.Any()).Return(sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistProduct,
+		},
+		{
+			name: "Serialized flag of a product in stock",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "armchair", Size: 4, Serialized: true},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().UpdateProduct(gomock.Any()).Return(repository.ErrProductInStock)
+			},
+			expectedErr: ErrProductInStock,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqProduct{UniqueCode: "olkiuj", Name: "armchair", Size: 4},
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().UpdateProduct(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockCatalogRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			res, err := NewCatalogService(r).UpdateProduct(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestCatalogService_DeleteProduct(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockCatalogRepository)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		expected      *DTO.Product
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(&model.Product{ID: 1, UniqueCode: "olkiuj", Name: "chair", Size: 3}, nil)
+			},
+			expected: &DTO.Product{ID: 1, UniqueCode: "olkiuj", Name: "chair", Size: 3},
+		},
+		{
+			name: "Non-existent product",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistProduct,
+		},
+		{
+			name: "Already deleted",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, repository.ErrProductDeleted)
+			},
+			expectedErr: ErrNonExistProduct,
+		},
+		{
+			name: "In stock",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, repository.ErrProductInStock)
+			},
+			expectedErr: ErrProductInStock,
+		},
+		{
+			name: "Stock in transit",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, repository.ErrProductInStock)
+			},
+			expectedErr: ErrProductInStock,
+		},
+		{
+			name: "Reserved",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, repository.ErrProductReserved)
+			},
+			expectedErr: ErrProductReserved,
+		},
+		{
+			name: "Repository error",
+			mockBehaviour: func(r mock_repository.MockCatalogRepository) {
+				r.EXPECT().DeleteProduct("olkiuj").Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockCatalogRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			res, err := NewCatalogService(r).DeleteProduct(&DTO.ReqGetProduct{UniqueCode: "olkiuj"})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
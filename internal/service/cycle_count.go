@@ -0,0 +1,242 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	"slices"
+)
+
+var ErrNonExistCycleCountId = errors.New("non-existent cycle count id")
+var ErrCycleCountOpen = errors.New("cycle count is already open in the warehouse")
+var ErrCycleCountNotOpen = errors.New("cycle count is not open")
+var ErrCycleCountClosed = errors.New("cycle count is closed")
+var ErrNotInCycleCount = errors.New("product is not included in the cycle count")
+var ErrSerializedCount = errors.New("serialized products cannot be cycle counted")
+var ErrCountSettled = errors.New("count of the product is already settled")
+var ErrNotCounted = errors.New("product was not counted")
+var ErrCountNotSettled = errors.New("cycle count has unsettled lines")
+
+// OpenCycleCount opens a count of the stock of a warehouse, limited to the requested products
+// if any. A warehouse can only have one count that is not closed.
+func (s *warehouseService) OpenCycleCount(req *DTO.ReqOpenCycleCount, user *AuthInfo) (*DTO.CycleCount, error) {
+	s.Logger.Info("start service OpenCycleCount")
+
+	if _, err := s.Repository.GetWarehouse(req.WarehouseID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistWarehouse
+		}
+		return nil, ErrInternal
+	}
+
+	codes := slices.Clone(req.UniqueCodes)
+	slices.Sort(codes)
+	count := &model.CycleCount{
+		WarehouseID:  req.WarehouseID,
+		ProductCodes: slices.Compact(codes),
+		CreatedBy:    user.ID,
+	}
+	err := s.Repository.OpenCycleCount(count)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrInvalidUniqueCode
+		case errors.Is(err, repository.ErrCycleCountOpen):
+			return nil, ErrCycleCountOpen
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	res := toCycleCountDTO(count, nil)
+	return &res, nil
+}
+
+// SubmitCycleCount records the quantities of products counted in an open count. A product can
+// be counted again while the count is open, replacing the previous quantity.
+func (s *warehouseService) SubmitCycleCount(req *DTO.ReqSubmitCycleCount, user *AuthInfo) (*DTO.ResSubmitCycleCount, error) {
+	s.Logger.Info("start service SubmitCycleCount")
+
+	count, err := s.getCycleCount(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != model.CycleCountOpen {
+		return nil, ErrCycleCountNotOpen
+	}
+
+	result := &DTO.ResSubmitCycleCount{
+		Successful:   make([]DTO.CycleCountLine, 0),
+		Unsuccessful: make([]string, 0),
+		Errors:       make([]string, 0),
+	}
+	for i, code := range req.UniqueCodes {
+		if req.Counts[i] < 0 {
+			result.Unsuccessful = append(result.Unsuccessful, code)
+			result.Errors = append(result.Errors, ErrInvalidCount.Error())
+			continue
+		}
+
+		line := &model.CycleCountLine{
+			CycleCountID: req.ID,
+			ProductCode:  code,
+			Counted:      req.Counts[i],
+			CountedBy:    user.ID,
+		}
+		if err = s.Repository.CountProduct(line); err != nil {
+			result.Unsuccessful = append(result.Unsuccessful, code)
+			result.Errors = append(result.Errors, countError(err).Error())
+			continue
+		}
+		result.Successful = append(result.Successful, toCycleCountLineDTO(line, ""))
+	}
+	return result, nil
+}
+
+// GetCycleCount shows a count with the variances of the counted products.
+func (s *warehouseService) GetCycleCount(req *DTO.ReqGetCycleCount) (*DTO.CycleCount, error) {
+	s.Logger.Info("start service GetCycleCount")
+
+	count, err := s.getCycleCount(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toCycleCountDTO(count, nil)
+	return &res, nil
+}
+
+// CloseCycleCount stops counting and applies the variances of the approved products as
+// adjustments with the cycle-count reason; without UniqueCodes the variances of all counted
+// products are approved, the others are skipped. A shortage held by live reservations is not
+// written off and is reported as unresolved. If a line cannot be settled, its error is
+// reported and the count stays closing, so closing it again retries the unsettled lines.
+func (s *warehouseService) CloseCycleCount(req *DTO.ReqCloseCycleCount, user *AuthInfo) (*DTO.CycleCount, error) {
+	s.Logger.Info("start service CloseCycleCount")
+
+	if !slices.Contains(s.Config.Adjustment.Reasons, model.AdjustmentCycleCount) {
+		return nil, ErrInvalidAdjustmentReason
+	}
+	count, err := s.getCycleCount(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status == model.CycleCountClosed {
+		return nil, ErrCycleCountClosed
+	}
+	for _, code := range req.UniqueCodes {
+		if !slices.ContainsFunc(count.Lines, func(line model.CycleCountLine) bool { return line.ProductCode == code }) {
+			return nil, ErrNotCounted
+		}
+	}
+
+	count, err = s.Repository.FreezeCycleCount(req.ID)
+	if err != nil {
+		return nil, countError(err)
+	}
+
+	errs := make(map[string]error)
+	for i := range count.Lines {
+		line := &count.Lines[i]
+		if line.Status != model.CountLineCounted {
+			continue
+		}
+		approved := len(req.UniqueCodes) == 0 || slices.Contains(req.UniqueCodes, line.ProductCode)
+		settled, adjustment, err := s.Repository.SettleCountLine(req.ID, line.ProductCode, approved, user.ID)
+		if err != nil {
+			errs[line.ProductCode] = countError(err)
+			continue
+		}
+		*line = *settled
+		// A shortage written off lowers left_count like any other adjustment.
+		if adjustment != nil && adjustment.Delta < 0 {
+			s.Alerts.dropped(count.WarehouseID, line.ProductCode, -adjustment.Delta)
+		}
+	}
+
+	if len(errs) == 0 {
+		count, err = s.Repository.CloseCycleCount(req.ID, user.ID)
+		if err != nil {
+			return nil, countError(err)
+		}
+	}
+
+	res := toCycleCountDTO(count, errs)
+	return &res, nil
+}
+
+func (s *warehouseService) getCycleCount(countID int) (*model.CycleCount, error) {
+	count, err := s.Repository.GetCycleCount(countID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistCycleCountId
+		}
+		return nil, ErrInternal
+	}
+	return count, nil
+}
+
+// countError maps an error of counting a product or settling its count to a service error.
+func countError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrInvalidUniqueCode
+	case errors.Is(err, repository.ErrCycleCountNotOpen):
+		return ErrCycleCountNotOpen
+	case errors.Is(err, repository.ErrCycleCountClosed):
+		return ErrCycleCountClosed
+	case errors.Is(err, repository.ErrNotInCycleCount):
+		return ErrNotInCycleCount
+	case errors.Is(err, repository.ErrSerializedCount):
+		return ErrSerializedCount
+	case errors.Is(err, repository.ErrCountSettled):
+		return ErrCountSettled
+	case errors.Is(err, repository.ErrCountNotSettled):
+		return ErrCountNotSettled
+	default:
+		return lineError(err)
+	}
+}
+
+func toCycleCountDTO(count *model.CycleCount, errs map[string]error) DTO.CycleCount {
+	res := DTO.CycleCount{
+		ID:          count.ID,
+		WarehouseID: count.WarehouseID,
+		UniqueCodes: count.ProductCodes,
+		Status:      count.Status,
+		CreatedBy:   count.CreatedBy,
+		ClosedBy:    count.ClosedBy,
+		CreatedAt:   count.CreatedAt,
+		ClosedAt:    count.ClosedAt,
+		Lines:       make([]DTO.CycleCountLine, 0, len(count.Lines)),
+	}
+	for i := range count.Lines {
+		lineErr := ""
+		if err, ok := errs[count.Lines[i].ProductCode]; ok {
+			lineErr = err.Error()
+		}
+		res.Lines = append(res.Lines, toCycleCountLineDTO(&count.Lines[i], lineErr))
+	}
+	return res
+}
+
+func toCycleCountLineDTO(line *model.CycleCountLine, lineErr string) DTO.CycleCountLine {
+	res := DTO.CycleCountLine{
+		UniqueCode:   line.ProductCode,
+		Counted:      line.Counted,
+		Expected:     line.Expected,
+		Variance:     line.Variance(),
+		Status:       line.Status,
+		Adjusted:     line.Adjusted,
+		AdjustmentID: line.AdjustmentID,
+		CountedBy:    line.CountedBy,
+		CountedAt:    line.CountedAt,
+		Error:        lineErr,
+	}
+	if line.Status == model.CountLineAdjusted {
+		res.Unresolved = line.Variance() - line.Adjusted
+	}
+	return res
+}
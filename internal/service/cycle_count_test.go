@@ -0,0 +1,333 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestToCycleCountLineDTO(t *testing.T) {
+	testTable := []struct {
+		name               string
+		line               model.CycleCountLine
+		expectedVariance   int
+		expectedUnresolved int
+	}{
+		{
+			name:             "Counted",
+			line:             model.CycleCountLine{Counted: 7, Expected: 10, Status: model.CountLineCounted},
+			expectedVariance: -3,
+		},
+		{
+			name:             "Surplus adjusted",
+			line:             model.CycleCountLine{Counted: 12, Expected: 10, Status: model.CountLineAdjusted, Adjusted: 2},
+			expectedVariance: 2,
+		},
+		{
+			name:             "Shortage written off",
+			line:             model.CycleCountLine{Counted: 7, Expected: 10, Status: model.CountLineAdjusted, Adjusted: -3},
+			expectedVariance: -3,
+		},
+		{
+			name:               "Shortage held by reservations",
+			line:               model.CycleCountLine{Counted: 7, Expected: 10, Status: model.CountLineAdjusted, Adjusted: -1},
+			expectedVariance:   -3,
+			expectedUnresolved: -2,
+		},
+		{
+			name:             "Skipped",
+			line:             model.CycleCountLine{Counted: 7, Expected: 10, Status: model.CountLineSkipped},
+			expectedVariance: -3,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			res := toCycleCountLineDTO(&test.line, "")
+			assert.Equal(t, test.expectedVariance, res.Variance)
+			assert.Equal(t, test.expectedUnresolved, res.Unresolved)
+		})
+	}
+}
+
+func TestCycleCount_Covers(t *testing.T) {
+	testTable := []struct {
+		name         string
+		productCodes []string
+		productCode  string
+		expected     bool
+	}{
+		{
+			name:        "Whole warehouse",
+			productCode: "olkiuj",
+			expected:    true,
+		},
+		{
+			name:         "Listed product",
+			productCodes: []string{"lkjhgf", "olkiuj"},
+			productCode:  "olkiuj",
+			expected:     true,
+		},
+		{
+			name:         "Other product",
+			productCodes: []string{"lkjhgf"},
+			productCode:  "olkiuj",
+			expected:     false,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			count := &model.CycleCount{ProductCodes: test.productCodes}
+			assert.Equal(t, test.expected, count.Covers(test.productCode))
+		})
+	}
+}
+
+func TestWarehouseService_CloseCycleCount(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	countedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	shortage := model.CycleCountLine{
+		ProductCode: "olkiuj", Counted: 7, Expected: 10, Status: model.CountLineCounted, CountedBy: "lol", CountedAt: countedAt,
+	}
+	surplus := model.CycleCountLine{
+		ProductCode: "lkjhgf", Counted: 7, Expected: 5, Status: model.CountLineCounted, CountedBy: "lol", CountedAt: countedAt,
+	}
+	matched := model.CycleCountLine{
+		ProductCode: "tghyuj", Counted: 4, Expected: 4, Status: model.CountLineMatched, CountedBy: "lol", CountedAt: countedAt,
+	}
+	// settled mimics the repository: an approved variance is adjusted, the other ones are skipped.
+	settled := func(line model.CycleCountLine, approved bool, adjustmentID int) *model.CycleCountLine {
+		if !approved {
+			line.Status = model.CountLineSkipped
+			return &line
+		}
+		line.Status, line.Adjusted, line.AdjustmentID = model.CountLineAdjusted, line.Variance(), adjustmentID
+		return &line
+	}
+	adjustment := func(line model.CycleCountLine, adjustmentID int) *model.Adjustment {
+		return &model.Adjustment{
+			ID: adjustmentID, WarehouseID: 2, ProductCode: line.ProductCode, Delta: line.Variance(),
+			Reason: model.AdjustmentCycleCount, Status: model.AdjustmentApplied, CreatedBy: "lol",
+		}
+	}
+	count := func(status string, lines ...model.CycleCountLine) *model.CycleCount {
+		return &model.CycleCount{ID: 5, WarehouseID: 2, Status: status, CreatedBy: "lol", Lines: lines}
+	}
+
+	shortageAdjusted := DTO.CycleCountLine{
+		UniqueCode: "olkiuj", Counted: 7, Expected: 10, Variance: -3, Status: model.CountLineAdjusted, Adjusted: -3,
+		AdjustmentID: 11, CountedBy: "lol", CountedAt: countedAt,
+	}
+	surplusAdjusted := DTO.CycleCountLine{
+		UniqueCode: "lkjhgf", Counted: 7, Expected: 5, Variance: 2, Status: model.CountLineAdjusted, Adjusted: 2,
+		AdjustmentID: 12, CountedBy: "lol", CountedAt: countedAt,
+	}
+	matchedLine := DTO.CycleCountLine{
+		UniqueCode: "tghyuj", Counted: 4, Expected: 4, Status: model.CountLineMatched, CountedBy: "lol", CountedAt: countedAt,
+	}
+	cycleCount := config.Config{Adjustment: config.AdjustmentConfig{Reasons: []string{"cycle-count"}}}
+
+	testTable := []struct {
+		name          string
+		config        config.Config
+		req           DTO.ReqCloseCycleCount
+		mockBehaviour mockBehaviour
+		expected      *DTO.CycleCount
+		expectedErr   error
+	}{
+		{
+			name:   "All lines settled",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage, surplus, matched), nil)
+				r.EXPECT().FreezeCycleCount(5).Return(count(model.CycleCountClosing, shortage, surplus, matched), nil)
+				r.EXPECT().SettleCountLine(5, "olkiuj", true, "lol").Return(
+					settled(shortage, true, 11), adjustment(shortage, 11), nil,
+				)
+				r.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+				r.EXPECT().SettleCountLine(5, "lkjhgf", true, "lol").Return(
+					settled(surplus, true, 12), adjustment(surplus, 12), nil,
+				)
+				r.EXPECT().CloseCycleCount(5, "lol").Return(count(
+					model.CycleCountClosed, *settled(shortage, true, 11), *settled(surplus, true, 12), matched,
+				), nil)
+			},
+			expected: &DTO.CycleCount{
+				ID: 5, WarehouseID: 2, Status: model.CycleCountClosed, CreatedBy: "lol",
+				Lines: []DTO.CycleCountLine{shortageAdjusted, surplusAdjusted, matchedLine},
+			},
+		},
+		{
+			name:   "Line not settled",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage, surplus, matched), nil)
+				r.EXPECT().FreezeCycleCount(5).Return(count(model.CycleCountClosing, shortage, surplus, matched), nil)
+				r.EXPECT().SettleCountLine(5, "olkiuj", true, "lol").Return(nil, nil, errors.New("connection refused"))
+				r.EXPECT().SettleCountLine(5, "lkjhgf", true, "lol").Return(
+					settled(surplus, true, 12), adjustment(surplus, 12), nil,
+				)
+			},
+			expected: &DTO.CycleCount{
+				ID: 5, WarehouseID: 2, Status: model.CycleCountClosing, CreatedBy: "lol",
+				Lines: []DTO.CycleCountLine{
+					{
+						UniqueCode: "olkiuj", Counted: 7, Expected: 10, Variance: -3, Status: model.CountLineCounted,
+						CountedBy: "lol", CountedAt: countedAt, Error: ErrInternal.Error(),
+					},
+					surplusAdjusted,
+					matchedLine,
+				},
+			},
+		},
+		{
+			name:   "Retry of a closing count",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(
+					count(model.CycleCountClosing, shortage, *settled(surplus, true, 12), matched), nil,
+				)
+				r.EXPECT().FreezeCycleCount(5).Return(
+					count(model.CycleCountClosing, shortage, *settled(surplus, true, 12), matched), nil,
+				)
+				r.EXPECT().SettleCountLine(5, "olkiuj", true, "lol").Return(
+					settled(shortage, true, 11), adjustment(shortage, 11), nil,
+				)
+				r.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+				r.EXPECT().CloseCycleCount(5, "lol").Return(count(
+					model.CycleCountClosed, *settled(shortage, true, 11), *settled(surplus, true, 12), matched,
+				), nil)
+			},
+			expected: &DTO.CycleCount{
+				ID: 5, WarehouseID: 2, Status: model.CycleCountClosed, CreatedBy: "lol",
+				Lines: []DTO.CycleCountLine{shortageAdjusted, surplusAdjusted, matchedLine},
+			},
+		},
+		{
+			name:   "Shortage held by reservations",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				held := model.CycleCountLine{
+					ProductCode: "olkiuj", Counted: 7, Expected: 10, Status: model.CountLineAdjusted, Adjusted: -1,
+					AdjustmentID: 11, CountedBy: "lol", CountedAt: countedAt,
+				}
+				written := adjustment(shortage, 11)
+				written.Delta = -1
+
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage), nil)
+				r.EXPECT().FreezeCycleCount(5).Return(count(model.CycleCountClosing, shortage), nil)
+				r.EXPECT().SettleCountLine(5, "olkiuj", true, "lol").Return(&held, written, nil)
+				r.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+				r.EXPECT().CloseCycleCount(5, "lol").Return(count(model.CycleCountClosed, held), nil)
+			},
+			expected: &DTO.CycleCount{
+				ID: 5, WarehouseID: 2, Status: model.CycleCountClosed, CreatedBy: "lol",
+				Lines: []DTO.CycleCountLine{{
+					UniqueCode: "olkiuj", Counted: 7, Expected: 10, Variance: -3, Status: model.CountLineAdjusted,
+					Adjusted: -1, Unresolved: -2, AdjustmentID: 11, CountedBy: "lol", CountedAt: countedAt,
+				}},
+			},
+		},
+		{
+			name:   "Approved subset",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5, UniqueCodes: []string{"lkjhgf"}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage, surplus), nil)
+				r.EXPECT().FreezeCycleCount(5).Return(count(model.CycleCountClosing, shortage, surplus), nil)
+				r.EXPECT().SettleCountLine(5, "olkiuj", false, "lol").Return(
+					settled(shortage, false, 0), nil, nil,
+				)
+				r.EXPECT().SettleCountLine(5, "lkjhgf", true, "lol").Return(
+					settled(surplus, true, 12), adjustment(surplus, 12), nil,
+				)
+				r.EXPECT().CloseCycleCount(5, "lol").Return(
+					count(model.CycleCountClosed, *settled(shortage, false, 0), *settled(surplus, true, 12)), nil,
+				)
+			},
+			expected: &DTO.CycleCount{
+				ID: 5, WarehouseID: 2, Status: model.CycleCountClosed, CreatedBy: "lol",
+				Lines: []DTO.CycleCountLine{
+					{
+						UniqueCode: "olkiuj", Counted: 7, Expected: 10, Variance: -3, Status: model.CountLineSkipped,
+						CountedBy: "lol", CountedAt: countedAt,
+					},
+					surplusAdjusted,
+				},
+			},
+		},
+		{
+			name:   "Approved product not counted",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5, UniqueCodes: []string{"qwerty"}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage, surplus), nil)
+			},
+			expectedErr: ErrNotCounted,
+		},
+		{
+			name:   "Closed count",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountClosed), nil)
+			},
+			expectedErr: ErrCycleCountClosed,
+		},
+		{
+			name:   "Closed by a concurrent request",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(5).Return(count(model.CycleCountOpen, shortage), nil)
+				r.EXPECT().FreezeCycleCount(5).Return(nil, repository.ErrCycleCountClosed)
+			},
+			expectedErr: ErrCycleCountClosed,
+		},
+		{
+			name:   "Non-existent count",
+			config: cycleCount,
+			req:    DTO.ReqCloseCycleCount{ID: 12},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetCycleCount(12).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistCycleCountId,
+		},
+		{
+			name:          "Cycle-count reason not configured",
+			req:           DTO.ReqCloseCycleCount{ID: 5},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidAdjustmentReason,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, test.config, notifier.NewLogNotifier())
+			res, err := s.CloseCycleCount(&test.req, &AuthInfo{ID: "lol", Role: warehouseWorker})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
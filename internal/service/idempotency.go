@@ -0,0 +1,91 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"example1/config"
+	"example1/internal/model"
+	"example1/internal/repository"
+	"example1/pkg/logger"
+	"time"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+var ErrRequestInProgress = errors.New("request with this idempotency key is in progress")
+
+type idempotencyService struct {
+	Repository repository.IdempotencyRepository
+	Config     config.Config
+	Logger     logger.Logger
+}
+
+func NewIdempotencyService(r repository.IdempotencyRepository, c config.Config) IdempotencyService {
+	return &idempotencyService{r, c, logger.Get()}
+}
+
+//go:generate mockgen -source=idempotency.go -destination=mocks/mock_idempotency.go
+
+type IdempotencyService interface {
+	Claim(userID, key string, request []byte) (*model.IdempotentRequest, error)
+	SaveResponse(userID, key string, statusCode int, response []byte) error
+	Release(userID, key string) error
+	DeleteExpired() (int64, error)
+}
+
+// Claim registers the first request with a key for the user and returns nil, so that it can be
+// handled. For a repeated request within the idempotency window it returns the stored request,
+// ErrRequestInProgress if the first one has not been answered yet or ErrIdempotencyKeyReused if
+// the key was used for a different request.
+func (s *idempotencyService) Claim(userID, key string, request []byte) (*model.IdempotentRequest, error) {
+	s.Logger.Info("start service Claim")
+
+	hash := sha256.Sum256(request)
+	req := &model.IdempotentRequest{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash[:]),
+	}
+
+	stored, err := s.Repository.Claim(req, time.Now().Add(-s.window()))
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if stored == nil {
+		return nil, nil
+	}
+
+	if stored.RequestHash != req.RequestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, ErrRequestInProgress
+	}
+	return stored, nil
+}
+
+func (s *idempotencyService) SaveResponse(userID, key string, statusCode int, response []byte) error {
+	s.Logger.Info("start service SaveResponse")
+
+	return s.Repository.SaveResponse(&model.IdempotentRequest{
+		UserID:     userID,
+		Key:        key,
+		StatusCode: statusCode,
+		Response:   response,
+	})
+}
+
+func (s *idempotencyService) Release(userID, key string) error {
+	s.Logger.Info("start service Release")
+	return s.Repository.Release(userID, key)
+}
+
+// DeleteExpired forgets the requests that are older than the idempotency window.
+func (s *idempotencyService) DeleteExpired() (int64, error) {
+	s.Logger.Info("start service DeleteExpired")
+	return s.Repository.DeleteBefore(time.Now().Add(-s.window()))
+}
+
+func (s *idempotencyService) window() time.Duration {
+	return time.Duration(s.Config.IdempotencyWindow) * time.Minute
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"time"
+)
+
+var ErrInvalidExpiryDate = errors.New("invalid expiry date")
+var ErrLotNumberRequired = errors.New("lot number is required for an expiry date")
+var ErrLotExpiryMismatch = errors.New("lot already exists with a different expiry date")
+var ErrInvalidDays = errors.New("invalid days")
+
+// GetExpiringLots lists the lots that still hold stock and expire within the given number of
+// days from today, including the ones that have already expired, earliest expiry first.
+func (s *warehouseService) GetExpiringLots(req *DTO.ReqGetExpiringLots) (*DTO.ResGetExpiringLots, error) {
+	s.Logger.Info("start service GetExpiringLots")
+
+	if req.Days < 0 {
+		return nil, ErrInvalidDays
+	}
+
+	year, month, day := time.Now().Date()
+	lots, err := s.Repository.ExpiringLots(&model.LotFilter{
+		WarehouseID: req.WarehouseID,
+		Before:      time.Date(year, month, day+req.Days, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResGetExpiringLots{Lots: make([]DTO.Lot, 0, len(lots))}
+	for i := range lots {
+		res.Lots = append(res.Lots, *toLotDTO(&lots[i]))
+	}
+	return res, nil
+}
+
+// receiptLot returns the lot number and expiry date of the i-th line of the receipt. Both are
+// optional, but an expiry date needs a lot number.
+func receiptLot(req *DTO.ReqReceiveProduct, i int) (string, *time.Time, error) {
+	lotNumber, expiryDate := "", ""
+	if i < len(req.LotNumbers) {
+		lotNumber = req.LotNumbers[i]
+	}
+	if i < len(req.ExpiryDates) {
+		expiryDate = req.ExpiryDates[i]
+	}
+	if expiryDate == "" {
+		return lotNumber, nil, nil
+	}
+	if lotNumber == "" {
+		return "", nil, ErrLotNumberRequired
+	}
+
+	expiresOn, err := time.Parse(time.DateOnly, expiryDate)
+	if err != nil {
+		return "", nil, ErrInvalidExpiryDate
+	}
+	return lotNumber, &expiresOn, nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func toLotDTO(lot *model.Lot) *DTO.Lot {
+	return &DTO.Lot{
+		ID:          lot.ID,
+		WarehouseID: lot.WarehouseID,
+		UniqueCode:  lot.ProductCode,
+		LotNumber:   lot.LotNumber,
+		ExpiryDate:  formatDate(lot.ExpiresOn),
+		TotalCount:  lot.TotalCount,
+		LeftCount:   lot.LeftCount,
+	}
+}
+
+func toLotCountDTOs(lots []model.LotCount) []DTO.LotCount {
+	var res []DTO.LotCount
+	for _, lot := range lots {
+		res = append(res, DTO.LotCount{
+			LotID:      lot.LotID,
+			LotNumber:  lot.LotNumber,
+			ExpiryDate: formatDate(lot.ExpiresOn),
+			Count:      lot.Count,
+		})
+	}
+	return res
+}
+
+// toTransferLotDTOs converts the lots of a transfer. They are identified by their numbers only,
+// as the transfer takes them from one warehouse and adds them to lots of another.
+func toTransferLotDTOs(lots []model.LotCount) []DTO.LotCount {
+	res := toLotCountDTOs(lots)
+	for i := range res {
+		res[i].LotID = 0
+	}
+	return res
+}
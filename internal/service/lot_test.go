@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestReceiptLot(t *testing.T) {
+	expiresOn := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name              string
+		req               DTO.ReqReceiveProduct
+		expectedLotNumber string
+		expectedExpiresOn *time.Time
+		expectedErr       error
+	}{
+		{
+			name:              "Without lot",
+			req:               DTO.ReqReceiveProduct{UniqueCodes: []string{"olkiuj"}},
+			expectedLotNumber: "",
+		},
+		{
+			name: "Lot without expiry date",
+			req: DTO.ReqReceiveProduct{
+				UniqueCodes: []string{"olkiuj"},
+				LotNumbers:  []string{"L-1024"},
+			},
+			expectedLotNumber: "L-1024",
+		},
+		{
+			name: "Lot with expiry date",
+			req: DTO.ReqReceiveProduct{
+				UniqueCodes: []string{"olkiuj"},
+				LotNumbers:  []string{"L-1024"},
+				ExpiryDates: []string{"2026-11-01"},
+			},
+			expectedLotNumber: "L-1024",
+			expectedExpiresOn: &expiresOn,
+		},
+		{
+			name: "Expiry date without lot",
+			req: DTO.ReqReceiveProduct{
+				UniqueCodes: []string{"olkiuj"},
+				LotNumbers:  []string{""},
+				ExpiryDates: []string{"2026-11-01"},
+			},
+			expectedErr: ErrLotNumberRequired,
+		},
+		{
+			name: "Invalid expiry date",
+			req: DTO.ReqReceiveProduct{
+				UniqueCodes: []string{"olkiuj"},
+				LotNumbers:  []string{"L-1024"},
+				ExpiryDates: []string{"01.11.2026"},
+			},
+			expectedErr: ErrInvalidExpiryDate,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			lotNumber, expiresOn, err := receiptLot(&test.req, 0)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedLotNumber, lotNumber)
+			assert.Equal(t, test.expectedExpiresOn, expiresOn)
+		})
+	}
+}
+
+func TestWarehouseService_GetExpiringLots(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	expired := today.AddDate(0, 0, -3)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqGetExpiringLots
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResGetExpiringLots
+		expectedErr   error
+	}{
+		{
+			name: "Within a week",
+			req:  DTO.ReqGetExpiringLots{WarehouseID: 2, Days: 7},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ExpiringLots(&model.LotFilter{WarehouseID: 2, Before: today.AddDate(0, 0, 7)}).Return(
+					[]model.Lot{{ID: 4, WarehouseID: 2, ProductCode: "olkiuj", LotNumber: "L-4", ExpiresOn: &expired,
+						TotalCount: 10, LeftCount: 5}},
+					nil,
+				)
+			},
+			expected: &DTO.ResGetExpiringLots{Lots: []DTO.Lot{{ID: 4, WarehouseID: 2, UniqueCode: "olkiuj",
+				LotNumber: "L-4", ExpiryDate: expired.Format(time.DateOnly), TotalCount: 10, LeftCount: 5}}},
+		},
+		{
+			name: "Expired only",
+			req:  DTO.ReqGetExpiringLots{},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ExpiringLots(&model.LotFilter{Before: today}).Return([]model.Lot{}, nil)
+			},
+			expected: &DTO.ResGetExpiringLots{Lots: []DTO.Lot{}},
+		},
+		{
+			name:          "Negative days",
+			req:           DTO.ReqGetExpiringLots{Days: -1},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidDays,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqGetExpiringLots{Days: 7},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ExpiringLots(gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetExpiringLots(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
@@ -6,6 +6,7 @@ package mock_service
 
 import (
 	DTO "example1/internal/DTO"
+	service "example1/internal/service"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,32 +35,182 @@ func (m *MockProductService) EXPECT() *MockProductServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelBackorder mocks base method.
+func (m *MockProductService) CancelBackorder(req *DTO.ReqCancelBackorder, user *service.AuthInfo) (*DTO.Backorder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBackorder", req, user)
+	ret0, _ := ret[0].(*DTO.Backorder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelBackorder indicates an expected call of CancelBackorder.
+func (mr *MockProductServiceMockRecorder) CancelBackorder(req, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBackorder", reflect.TypeOf((*MockProductService)(nil).CancelBackorder), req, user)
+}
+
+// CancelReservation mocks base method.
+func (m *MockProductService) CancelReservation(req *DTO.ReqChangeReservationStatus, user *service.AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReservation", req, user)
+	ret0, _ := ret[0].(*DTO.ResChangeReservationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReservation indicates an expected call of CancelReservation.
+func (mr *MockProductServiceMockRecorder) CancelReservation(req, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReservation", reflect.TypeOf((*MockProductService)(nil).CancelReservation), req, user)
+}
+
+// ConfirmReservation mocks base method.
+func (m *MockProductService) ConfirmReservation(req *DTO.ReqChangeReservationStatus, user *service.AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmReservation", req, user)
+	ret0, _ := ret[0].(*DTO.ResChangeReservationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmReservation indicates an expected call of ConfirmReservation.
+func (mr *MockProductServiceMockRecorder) ConfirmReservation(req, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmReservation", reflect.TypeOf((*MockProductService)(nil).ConfirmReservation), req, user)
+}
+
+// ExtendReservation mocks base method.
+func (m *MockProductService) ExtendReservation(req *DTO.ReqExtendReservation) (*DTO.ResExtendReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendReservation", req)
+	ret0, _ := ret[0].(*DTO.ResExtendReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendReservation indicates an expected call of ExtendReservation.
+func (mr *MockProductServiceMockRecorder) ExtendReservation(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendReservation", reflect.TypeOf((*MockProductService)(nil).ExtendReservation), req)
+}
+
 // FreeReservation mocks base method.
-func (m *MockProductService) FreeReservation(reservations *DTO.ReqFreeReservation) (*DTO.ResFreeReservation, error) {
+func (m *MockProductService) FreeReservation(reservations *DTO.ReqFreeReservation, user *service.AuthInfo) (*DTO.ResFreeReservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FreeReservation", reservations)
+	ret := m.ctrl.Call(m, "FreeReservation", reservations, user)
 	ret0, _ := ret[0].(*DTO.ResFreeReservation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FreeReservation indicates an expected call of FreeReservation.
-func (mr *MockProductServiceMockRecorder) FreeReservation(reservations interface{}) *gomock.Call {
+func (mr *MockProductServiceMockRecorder) FreeReservation(reservations, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreeReservation", reflect.TypeOf((*MockProductService)(nil).FreeReservation), reservations, user)
+}
+
+// GetReservation mocks base method.
+func (m *MockProductService) GetReservation(req *DTO.ReqGetReservation) (*DTO.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", req)
+	ret0, _ := ret[0].(*DTO.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation.
+func (mr *MockProductServiceMockRecorder) GetReservation(req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreeReservation", reflect.TypeOf((*MockProductService)(nil).FreeReservation), reservations)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*MockProductService)(nil).GetReservation), req)
+}
+
+// ListBackorders mocks base method.
+func (m *MockProductService) ListBackorders(req *DTO.ReqListBackorders, user *service.AuthInfo) (*DTO.ResListBackorders, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackorders", req, user)
+	ret0, _ := ret[0].(*DTO.ResListBackorders)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBackorders indicates an expected call of ListBackorders.
+func (mr *MockProductServiceMockRecorder) ListBackorders(req, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackorders", reflect.TypeOf((*MockProductService)(nil).ListBackorders), req, user)
+}
+
+// ListReservations mocks base method.
+func (m *MockProductService) ListReservations(req *DTO.ReqListReservations) (*DTO.ResListReservations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReservations", req)
+	ret0, _ := ret[0].(*DTO.ResListReservations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReservations indicates an expected call of ListReservations.
+func (mr *MockProductServiceMockRecorder) ListReservations(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReservations", reflect.TypeOf((*MockProductService)(nil).ListReservations), req)
+}
+
+// Quote mocks base method.
+func (m *MockProductService) Quote(req *DTO.ReqReserveProduct) (*DTO.ResQuoteReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", req)
+	ret0, _ := ret[0].(*DTO.ResQuoteReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote.
+func (mr *MockProductServiceMockRecorder) Quote(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockProductService)(nil).Quote), req)
+}
+
+// ReleaseExpired mocks base method.
+func (m *MockProductService) ReleaseExpired() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpired")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpired indicates an expected call of ReleaseExpired.
+func (mr *MockProductServiceMockRecorder) ReleaseExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpired", reflect.TypeOf((*MockProductService)(nil).ReleaseExpired))
 }
 
 // Reserve mocks base method.
-func (m *MockProductService) Reserve(reservation *DTO.ReqReserveProduct) (*DTO.ResReserveProduct, error) {
+func (m *MockProductService) Reserve(reservation *DTO.ReqReserveProduct, user *service.AuthInfo) (*DTO.ResReserveProduct, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Reserve", reservation)
+	ret := m.ctrl.Call(m, "Reserve", reservation, user)
 	ret0, _ := ret[0].(*DTO.ResReserveProduct)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Reserve indicates an expected call of Reserve.
-func (mr *MockProductServiceMockRecorder) Reserve(reservation interface{}) *gomock.Call {
+func (mr *MockProductServiceMockRecorder) Reserve(reservation, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockProductService)(nil).Reserve), reservation, user)
+}
+
+// ShipReservation mocks base method.
+func (m *MockProductService) ShipReservation(req *DTO.ReqChangeReservationStatus, user *service.AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShipReservation", req, user)
+	ret0, _ := ret[0].(*DTO.ResChangeReservationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShipReservation indicates an expected call of ShipReservation.
+func (mr *MockProductServiceMockRecorder) ShipReservation(req, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockProductService)(nil).Reserve), reservation)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShipReservation", reflect.TypeOf((*MockProductService)(nil).ShipReservation), req, user)
 }
@@ -3,10 +3,14 @@ package service
 import (
 	"database/sql"
 	"errors"
+	"example1/config"
 	"example1/internal/DTO"
 	"example1/internal/model"
 	"example1/internal/repository"
 	"example1/pkg/logger"
+	"example1/pkg/notifier"
+	"fmt"
+	"time"
 )
 
 var ErrInvalidUniqueCode = errors.New("invalid unique code")
@@ -15,25 +19,48 @@ var ErrInvalidWarehouse = errors.New("invalid warehouse id")
 var ErrWarehouseUnavailable = errors.New("warehouse is unavailable")
 var ErrNotEnoughProduct = errors.New("not enough product")
 var ErrNonExistReservationId = errors.New("non-existent reservation id")
+var ErrInvalidCount = errors.New("invalid count")
+var ErrRolledBack = errors.New("rolled back")
+var ErrInvalidTTL = errors.New("invalid ttl")
+var ErrReservationExpired = errors.New("reservation has expired")
+var ErrReservationNotExpiring = errors.New("reservation does not expire")
+var ErrInvalidTransition = errors.New("invalid status transition")
+var ErrForbidden = errors.New("forbidden")
+var ErrInvalidStatus = errors.New("invalid status")
+var ErrInvalidLimit = errors.New("invalid limit")
+var ErrAtomicBackorder = errors.New("backorder is not supported for atomic reservations")
 
 type productService struct {
 	ProductRepository   repository.ProductRepository
 	WarehouseRepository repository.WarehouseRepository
+	Config              config.Config
+	Alerts              *stockAlerts
 	Logger              logger.Logger
 }
 
-func NewProductService(r1 repository.ProductRepository, r2 repository.WarehouseRepository) ProductService {
-	return &productService{r1, r2, logger.Get()}
+func NewProductService(r1 repository.ProductRepository, r2 repository.WarehouseRepository, c config.Config,
+	n notifier.Notifier) ProductService {
+	return &productService{r1, r2, c, newStockAlerts(r2, n), logger.Get()}
 }
 
 //go:generate mockgen -source=product.go -destination=mocks/mock.go
 
 type ProductService interface {
-	Reserve(reservation *DTO.ReqReserveProduct) (*DTO.ResReserveProduct, error)
-	FreeReservation(reservations *DTO.ReqFreeReservation) (*DTO.ResFreeReservation, error)
+	Reserve(reservation *DTO.ReqReserveProduct, user *AuthInfo) (*DTO.ResReserveProduct, error)
+	FreeReservation(reservations *DTO.ReqFreeReservation, user *AuthInfo) (*DTO.ResFreeReservation, error)
+	ConfirmReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error)
+	ShipReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error)
+	CancelReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error)
+	GetReservation(req *DTO.ReqGetReservation) (*DTO.Reservation, error)
+	ListReservations(req *DTO.ReqListReservations) (*DTO.ResListReservations, error)
+	ExtendReservation(req *DTO.ReqExtendReservation) (*DTO.ResExtendReservation, error)
+	ReleaseExpired() (int, error)
+	CancelBackorder(req *DTO.ReqCancelBackorder, user *AuthInfo) (*DTO.Backorder, error)
+	ListBackorders(req *DTO.ReqListBackorders, user *AuthInfo) (*DTO.ResListBackorders, error)
+	Quote(req *DTO.ReqReserveProduct) (*DTO.ResQuoteReservation, error)
 }
 
-func (s *productService) Reserve(reservation *DTO.ReqReserveProduct) (*DTO.ResReserveProduct, error) {
+func (s *productService) Reserve(reservation *DTO.ReqReserveProduct, user *AuthInfo) (*DTO.ResReserveProduct, error) {
 	s.Logger.Info("start service Reserve")
 
 	result := &DTO.ResReserveProduct{
@@ -42,6 +69,13 @@ func (s *productService) Reserve(reservation *DTO.ReqReserveProduct) (*DTO.ResRe
 		Errors:       make([]string, 0),
 	}
 
+	if err := validateReserveRequest(reservation); err != nil {
+		return nil, err
+	}
+	if reservation.Split {
+		return s.reserveSplit(reservation, user, result)
+	}
+
 	available, err := s.WarehouseRepository.CheckAvailable(reservation.WarehouseID)
 	if err != nil {
 		return nil, ErrInvalidWarehouse
@@ -52,98 +86,368 @@ func (s *productService) Reserve(reservation *DTO.ReqReserveProduct) (*DTO.ResRe
 		return nil, ErrWarehouseUnavailable
 	}
 
+	expiresAt := s.expiresAt(reservation.TTL)
+	lines := make([]*model.Reservation, len(reservation.UniqueCodes))
 	for i := 0; i < len(reservation.UniqueCodes); i++ {
-		re := &model.Reservation{
+		lines[i] = &model.Reservation{
 			WarehouseID: reservation.WarehouseID,
 			ProductCode: reservation.UniqueCodes[i],
 			Count:       reservation.Counts[i],
+			ExpiresAt:   expiresAt,
+			UserID:      user.ID,
 		}
+	}
 
-		leftCount, err := s.ProductRepository.GetLeftCount(re.ProductCode, re.WarehouseID)
+	if reservation.Atomic {
+		return s.reserveAll(lines, result)
+	}
+
+	for _, re := range lines {
+		var backorder *DTO.Backorder
+		_, err := s.ProductRepository.ReserveProduct(re)
+		if err != nil && reservation.Backorder && errors.Is(err, repository.ErrNotEnoughLeft) {
+			backorder, err = s.backorder(re, reservation.TTL)
+		}
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
-				result.Errors = append(result.Errors, ErrInvalidUniqueCode.Error())
-				continue
-			}
 			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
-			result.Errors = append(result.Errors, ErrInternal.Error())
+			result.Errors = append(result.Errors, lineError(err).Error())
 			continue
 		}
-
-		if leftCount < re.Count {
-			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
-			result.Errors = append(result.Errors, ErrNotEnoughProduct.Error())
-			continue
+		if backorder != nil {
+			result.Backordered = append(result.Backordered, *backorder)
 		}
-		err = s.ProductRepository.ChangeLeftCount(re.ProductCode, re.WarehouseID, leftCount-re.Count)
-		if err != nil {
-			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
-			result.Errors = append(result.Errors, ErrInternal.Error())
+		if re.ID == 0 {
 			continue
 		}
-		re, err = s.ProductRepository.ReserveProduct(re)
+
+		successful := DTO.Successful{
+			ID:            re.ID,
+			UniqueCode:    re.ProductCode,
+			ExpiresAt:     re.ExpiresAt,
+			Lots:          toLotCountDTOs(re.Lots),
+			SerialNumbers: re.SerialNumbers,
+		}
+		// A partly backordered line reports how much of it was reserved now.
+		if backorder != nil {
+			successful.Count = re.Count
+		}
+		result.Successful = append(result.Successful, successful)
+		s.Alerts.dropped(re.WarehouseID, re.ProductCode, re.Count)
+	}
+
+	return result, nil
+}
+
+// validateReserveRequest returns an error if the options of the reservation request do not go together.
+func validateReserveRequest(reservation *DTO.ReqReserveProduct) error {
+	if reservation.TTL < 0 {
+		return ErrInvalidTTL
+	}
+	if reservation.Atomic && reservation.Backorder {
+		return ErrAtomicBackorder
+	}
+	if reservation.Split && reservation.Backorder {
+		return ErrSplitBackorder
+	}
+	return nil
+}
+
+// expiresAt returns the expiry for a reservation made now with the given TTL in minutes,
+// falling back to the configured default. It returns nil if the reservation never expires.
+func (s *productService) expiresAt(ttl int) *time.Time {
+	ttl = s.ttl(ttl)
+	if ttl == 0 {
+		return nil
+	}
+
+	t := time.Now().Add(time.Duration(ttl) * time.Minute)
+	return &t
+}
+
+// ttl returns the TTL in minutes of a reservation made with the given one, falling back to the
+// configured default. It returns 0 if the reservation never expires.
+func (s *productService) ttl(ttl int) int {
+	if ttl == 0 {
+		ttl = s.Config.ReservationTTL
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// reserveAll reserves either every line or none of them. When any line fails, the lines that
+// could have been reserved are reported as rolled back alongside the actual failures.
+func (s *productService) reserveAll(lines []*model.Reservation, result *DTO.ResReserveProduct) (*DTO.ResReserveProduct, error) {
+	errs, err := s.ProductRepository.ReserveProducts(lines)
+	if err != nil {
+		s.Logger.Error(err)
+		return nil, ErrInternal
+	}
+
+	failed := false
+	for _, err := range errs {
 		if err != nil {
-			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
-			result.Errors = append(result.Errors, ErrInternal.Error())
-		} else {
-			s := DTO.Successful{
-				ID:         re.ID,
-				UniqueCode: re.ProductCode,
-			}
-			result.Successful = append(result.Successful, s)
+			failed = true
+			break
 		}
+	}
 
+	for i, re := range lines {
+		switch {
+		case errs[i] != nil:
+			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
+			result.Errors = append(result.Errors, lineError(errs[i]).Error())
+		case failed:
+			result.Unsuccessful = append(result.Unsuccessful, re.ProductCode)
+			result.Errors = append(result.Errors, ErrRolledBack.Error())
+		default:
+			result.Successful = append(result.Successful, DTO.Successful{
+				ID:            re.ID,
+				UniqueCode:    re.ProductCode,
+				ExpiresAt:     re.ExpiresAt,
+				Lots:          toLotCountDTOs(re.Lots),
+				SerialNumbers: re.SerialNumbers,
+			})
+			s.Alerts.dropped(re.WarehouseID, re.ProductCode, re.Count)
+		}
 	}
 
 	return result, nil
 }
 
-func (s *productService) FreeReservation(reservations *DTO.ReqFreeReservation) (*DTO.ResFreeReservation, error) {
+func (s *productService) FreeReservation(reservations *DTO.ReqFreeReservation, user *AuthInfo) (*DTO.ResFreeReservation, error) {
 	s.Logger.Info("start service FreeReservation")
 
 	result := DTO.ResFreeReservation{
 		Successful:   make([]int, 0),
+		Restored:     make([]int, 0),
 		Unsuccessful: make([]int, 0),
 		Errors:       make([]string, 0),
 	}
 
 	for i := 0; i < len(reservations.ID); i++ {
-		warehouseID, err := s.ProductRepository.GetWarehouseByReservationID(reservations.ID[i])
+		err := s.authorizeReservation(reservations.ID[i], user, true)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				result.Unsuccessful = append(result.Unsuccessful, reservations.ID[i])
-				result.Errors = append(result.Errors, ErrNonExistReservationId.Error())
-				continue
-			}
 			result.Unsuccessful = append(result.Unsuccessful, reservations.ID[i])
-			result.Errors = append(result.Errors, ErrInternal.Error())
+			result.Errors = append(result.Errors, err.Error())
 			continue
 		}
 
-		available, err := s.WarehouseRepository.CheckAvailable(warehouseID)
+		re, err := s.ProductRepository.ChangeStatus(reservations.ID[i], model.StatusCancelled, user.ID)
 		if err != nil {
 			result.Unsuccessful = append(result.Unsuccessful, reservations.ID[i])
-			result.Errors = append(result.Errors, ErrInternal.Error())
+			result.Errors = append(result.Errors, statusError(err, re, model.StatusCancelled).Error())
 			continue
 		}
 
-		if available == false {
-			result.Unsuccessful = append(result.Unsuccessful, reservations.ID[i])
-			result.Errors = append(result.Errors, ErrWarehouseUnavailable.Error())
-			continue
+		result.Successful = append(result.Successful, reservations.ID[i])
+		result.Restored = append(result.Restored, re.Count)
+
+	}
+
+	return &result, nil
+}
+
+func (s *productService) ExtendReservation(req *DTO.ReqExtendReservation) (*DTO.ResExtendReservation, error) {
+	s.Logger.Info("start service ExtendReservation")
+
+	if req.TTL <= 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	expiresAt, err := s.ProductRepository.ExtendReservation(req.ID, time.Duration(req.TTL)*time.Minute, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNonExistReservationId
+		case errors.Is(err, repository.ErrReservationExpired):
+			return nil, ErrReservationExpired
+		case errors.Is(err, repository.ErrReservationNotExpiring):
+			return nil, ErrReservationNotExpiring
+		default:
+			return nil, ErrInternal
 		}
+	}
 
-		err = s.ProductRepository.DeleteReservation(reservations.ID[i])
-		if err != nil {
-			result.Unsuccessful = append(result.Unsuccessful, reservations.ID[i])
-			result.Errors = append(result.Errors, ErrInternal.Error())
-			continue
+	return &DTO.ResExtendReservation{ID: req.ID, ExpiresAt: expiresAt}, nil
+}
+
+// ReleaseExpired returns the stock of every expired reservation to its warehouse and reports
+// how many reservations were released.
+func (s *productService) ReleaseExpired() (int, error) {
+	s.Logger.Info("start service ReleaseExpired")
+
+	released, err := s.ProductRepository.ReleaseExpired(time.Now())
+	if err != nil {
+		s.Logger.Error(err)
+		return 0, err
+	}
+
+	for _, re := range released {
+		s.Logger.Infof("released expired reservation %d: %d of %s in warehouse %d",
+			re.ID, re.Count, re.ProductCode, re.WarehouseID)
+	}
+	return len(released), nil
+}
+
+// ConfirmReservation marks a reserved reservation as paid. Confirmed reservations no longer expire.
+func (s *productService) ConfirmReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	s.Logger.Info("start service ConfirmReservation")
+	return s.changeStatus(req.ID, model.StatusConfirmed, user)
+}
+
+// ShipReservation marks a confirmed reservation as shipped, removing its count from the warehouse for good.
+func (s *productService) ShipReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	s.Logger.Info("start service ShipReservation")
+	return s.changeStatus(req.ID, model.StatusShipped, user)
+}
+
+// CancelReservation cancels a reservation that has not been shipped, returning its count to left_count.
+func (s *productService) CancelReservation(req *DTO.ReqChangeReservationStatus, user *AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	s.Logger.Info("start service CancelReservation")
+	return s.changeStatus(req.ID, model.StatusCancelled, user)
+}
+
+func (s *productService) changeStatus(reservationID int, status string, user *AuthInfo) (*DTO.ResChangeReservationStatus, error) {
+	err := s.authorizeReservation(reservationID, user, status == model.StatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := s.ProductRepository.ChangeStatus(reservationID, status, user.ID)
+	if err != nil {
+		return nil, statusError(err, re, status)
+	}
+
+	return &DTO.ResChangeReservationStatus{ID: re.ID, Status: re.Status}, nil
+}
+
+// authorizeReservation verifies that the reservation exists and its warehouse is available. If
+// ownerOnly is set, only the user who made the reservation or an admin may change it.
+func (s *productService) authorizeReservation(reservationID int, user *AuthInfo, ownerOnly bool) error {
+	re, err := s.ProductRepository.GetReservation(reservationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNonExistReservationId
 		}
+		return ErrInternal
+	}
 
-		result.Successful = append(result.Successful, reservations.ID[i])
+	if ownerOnly && user.Role != admin && re.UserID != user.ID {
+		return ErrForbidden
+	}
 
+	available, err := s.WarehouseRepository.CheckAvailable(re.WarehouseID)
+	if err != nil {
+		return ErrInternal
+	}
+	if available == false {
+		return ErrWarehouseUnavailable
 	}
 
-	return &result, nil
+	return nil
+}
+
+func (s *productService) GetReservation(req *DTO.ReqGetReservation) (*DTO.Reservation, error) {
+	s.Logger.Info("start service GetReservation")
+
+	re, err := s.ProductRepository.GetReservation(req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistReservationId
+		}
+		return nil, ErrInternal
+	}
+
+	res := toReservationDTO(re)
+	return &res, nil
+}
+
+// ListReservations lists reservations matching the request in ID order. NextCursor is set when
+// there may be more of them and is passed as Cursor to get the next page.
+func (s *productService) ListReservations(req *DTO.ReqListReservations) (*DTO.ResListReservations, error) {
+	s.Logger.Info("start service ListReservations")
+
+	if req.Status != "" && !model.IsStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	limit, err := pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := s.ProductRepository.ListReservations(&model.ReservationFilter{
+		WarehouseID: req.WarehouseID,
+		ProductCode: req.UniqueCode,
+		UserID:      req.Owner,
+		Status:      req.Status,
+		Cursor:      req.Cursor,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResListReservations{Reservations: make([]DTO.Reservation, 0, len(reservations))}
+	for i := range reservations {
+		res.Reservations = append(res.Reservations, toReservationDTO(&reservations[i]))
+	}
+	if len(reservations) == limit {
+		res.NextCursor = reservations[len(reservations)-1].ID
+	}
+	return res, nil
+}
+
+func toReservationDTO(re *model.Reservation) DTO.Reservation {
+	return DTO.Reservation{
+		ID:            re.ID,
+		WarehouseID:   re.WarehouseID,
+		UniqueCode:    re.ProductCode,
+		Count:         re.Count,
+		Status:        re.Status,
+		Owner:         re.UserID,
+		CreatedAt:     re.CreatedAt,
+		ExpiresAt:     re.ExpiresAt,
+		Lots:          toLotCountDTOs(re.Lots),
+		SerialNumbers: re.SerialNumbers,
+	}
+}
+
+// statusError maps a repository error for a status change of re to the error reported to the client.
+func statusError(err error, re *model.Reservation, status string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNonExistReservationId
+	case errors.Is(err, repository.ErrInvalidTransition):
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, re.Status, status)
+	default:
+		return ErrInternal
+	}
+}
+
+// lineError maps a repository error for a single line of a request to the error reported to the client.
+func lineError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrInvalidUniqueCode
+	case errors.Is(err, repository.ErrNotEnoughLeft):
+		return ErrNotEnoughProduct
+	case errors.Is(err, repository.ErrInvalidCount):
+		return ErrInvalidCount
+	case errors.Is(err, repository.ErrCapacityExceeded):
+		return ErrCapacityExceeded
+	case errors.Is(err, repository.ErrLotExpiryMismatch):
+		return ErrLotExpiryMismatch
+	case errors.Is(err, repository.ErrSerialCountMismatch):
+		return ErrSerialCountMismatch
+	case errors.Is(err, repository.ErrNotSerialized):
+		return ErrNotSerialized
+	case errors.Is(err, repository.ErrSerialExists):
+		return ErrSerialExists
+	case errors.Is(err, repository.ErrSerialNotAvailable):
+		return ErrSerialNotAvailable
+	default:
+		return ErrInternal
+	}
 }
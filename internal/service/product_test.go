@@ -0,0 +1,466 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestProductService_FreeReservation(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqFreeReservation
+		user          AuthInfo
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResFreeReservation
+	}{
+		{
+			name: "Counts restored",
+			req:  DTO.ReqFreeReservation{ID: []int{3, 4}},
+			user: AuthInfo{ID: "lol", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(3, model.StatusCancelled, "lol").Return(
+					&model.Reservation{ID: 3, WarehouseID: 2, Count: 1000, Status: model.StatusCancelled, UserID: "lol"},
+					nil,
+				)
+				p.EXPECT().GetReservation(4).Return(&model.Reservation{ID: 4, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(4, model.StatusCancelled, "lol").Return(
+					&model.Reservation{ID: 4, WarehouseID: 2, Count: 1200, Status: model.StatusCancelled, UserID: "lol"},
+					nil,
+				)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{3, 4},
+				Restored:     []int{1000, 1200},
+				Unsuccessful: []int{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Admin frees a reservation of another user",
+			req:  DTO.ReqFreeReservation{ID: []int{3}},
+			user: AuthInfo{ID: "root", Role: admin},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(3, model.StatusCancelled, "root").Return(
+					&model.Reservation{ID: 3, WarehouseID: 2, Count: 1000, Status: model.StatusCancelled, UserID: "lol"},
+					nil,
+				)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{3},
+				Restored:     []int{1000},
+				Unsuccessful: []int{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Reservation of another user",
+			req:  DTO.ReqFreeReservation{ID: []int{3}},
+			user: AuthInfo{ID: "kek", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{},
+				Restored:     []int{},
+				Unsuccessful: []int{3},
+				Errors:       []string{ErrForbidden.Error()},
+			},
+		},
+		{
+			name: "Already shipped",
+			req:  DTO.ReqFreeReservation{ID: []int{3, 4}},
+			user: AuthInfo{ID: "lol", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(3, model.StatusCancelled, "lol").Return(
+					&model.Reservation{ID: 3, WarehouseID: 2, Count: 1000, Status: model.StatusShipped, UserID: "lol"},
+					repository.ErrInvalidTransition,
+				)
+				p.EXPECT().GetReservation(4).Return(&model.Reservation{ID: 4, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(4, model.StatusCancelled, "lol").Return(
+					&model.Reservation{ID: 4, WarehouseID: 2, Count: 1200, Status: model.StatusCancelled, UserID: "lol"},
+					nil,
+				)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{4},
+				Restored:     []int{1200},
+				Unsuccessful: []int{3},
+				Errors:       []string{"invalid status transition from shipped to cancelled"},
+			},
+		},
+		{
+			name: "Non-existent reservation",
+			req:  DTO.ReqFreeReservation{ID: []int{12}},
+			user: AuthInfo{ID: "lol", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(12).Return(nil, sql.ErrNoRows)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{},
+				Restored:     []int{},
+				Unsuccessful: []int{12},
+				Errors:       []string{ErrNonExistReservationId.Error()},
+			},
+		},
+		{
+			name: "Unavailable warehouse",
+			req:  DTO.ReqFreeReservation{ID: []int{3}},
+			user: AuthInfo{ID: "lol", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(false, nil)
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{},
+				Restored:     []int{},
+				Unsuccessful: []int{3},
+				Errors:       []string{ErrWarehouseUnavailable.Error()},
+			},
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqFreeReservation{ID: []int{3}},
+			user: AuthInfo{ID: "lol", Role: productWorker},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{ID: 3, WarehouseID: 2, UserID: "lol"}, nil)
+				w.EXPECT().CheckAvailable(2).Return(true, nil)
+				p.EXPECT().ChangeStatus(3, model.StatusCancelled, "lol").Return(nil, errors.New("connection refused"))
+			},
+			expected: &DTO.ResFreeReservation{
+				Successful:   []int{},
+				Restored:     []int{},
+				Unsuccessful: []int{3},
+				Errors:       []string{ErrInternal.Error()},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			warehouseRepository := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*productRepository, *warehouseRepository)
+
+			s := NewProductService(productRepository, warehouseRepository, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.FreeReservation(&test.req, &test.user)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestProductService_ReleaseExpired(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository)
+
+	started := time.Now()
+	expired := started.Add(-time.Minute)
+	expiring := started.Add(time.Hour)
+	// stored are the reservations in the database; the repository cancels only those that are
+	// still reserved and expired before now.
+	stored := []model.Reservation{
+		{ID: 1, WarehouseID: 2, ProductCode: "olkiuj", Count: 10, Status: model.StatusReserved, ExpiresAt: &expired},
+		{ID: 2, WarehouseID: 2, ProductCode: "olkiuj", Count: 20, Status: model.StatusReserved, ExpiresAt: &expiring},
+		{ID: 3, WarehouseID: 2, ProductCode: "olkiuj", Count: 30, Status: model.StatusConfirmed, ExpiresAt: &expired},
+		{ID: 4, WarehouseID: 2, ProductCode: "olkiuj", Count: 40, Status: model.StatusReserved},
+		{ID: 5, WarehouseID: 2, ProductCode: "tghyuj", Count: 50, Status: model.StatusReserved, ExpiresAt: &expired},
+	}
+	release := func(now time.Time) ([]model.Reservation, error) {
+		var released []model.Reservation
+		for _, re := range stored {
+			if re.Status == model.StatusReserved && re.ExpiresAt != nil && re.ExpiresAt.Before(now) {
+				re.Status = model.StatusCancelled
+				released = append(released, re)
+			}
+		}
+		return released, nil
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		expected      int
+		expectedErr   error
+	}{
+		{
+			name: "Only reserved past expiry",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ReleaseExpired(gomock.Any()).DoAndReturn(release)
+			},
+			expected: 2,
+		},
+		{
+			name: "Nothing expired",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ReleaseExpired(gomock.Any()).Return(nil, nil)
+			},
+			expected: 0,
+		},
+		{
+			name: "Repository error",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ReleaseExpired(gomock.Any()).Return(nil, sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			test.mockBehaviour(*productRepository)
+
+			s := NewProductService(productRepository, nil, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.ReleaseExpired()
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestProductService_ExtendReservation(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository)
+
+	extended := time.Date(2026, 10, 18, 13, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqExtendReservation
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResExtendReservation
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			req:  DTO.ReqExtendReservation{ID: 3, TTL: 30},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ExtendReservation(3, 30*time.Minute, gomock.Any()).Return(extended, nil)
+			},
+			expected: &DTO.ResExtendReservation{ID: 3, ExpiresAt: extended},
+		},
+		{
+			name:          "Invalid ttl",
+			req:           DTO.ReqExtendReservation{ID: 3},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {},
+			expectedErr:   ErrInvalidTTL,
+		},
+		{
+			name: "Already expired",
+			req:  DTO.ReqExtendReservation{ID: 3, TTL: 30},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ExtendReservation(3, 30*time.Minute, gomock.Any()).Return(time.Time{}, repository.ErrReservationExpired)
+			},
+			expectedErr: ErrReservationExpired,
+		},
+		{
+			name: "Confirmed reservation",
+			req:  DTO.ReqExtendReservation{ID: 3, TTL: 30},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ExtendReservation(3, 30*time.Minute, gomock.Any()).
+					Return(time.Time{}, repository.ErrReservationNotExpiring)
+			},
+			expectedErr: ErrReservationNotExpiring,
+		},
+		{
+			name: "Non-existent reservation",
+			req:  DTO.ReqExtendReservation{ID: 12, TTL: 30},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ExtendReservation(12, 30*time.Minute, gomock.Any()).Return(time.Time{}, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistReservationId,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			test.mockBehaviour(*productRepository)
+
+			s := NewProductService(productRepository, nil, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.ExtendReservation(&test.req)
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestProductService_GetReservation(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository)
+
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		expected      *DTO.Reservation
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().GetReservation(3).Return(&model.Reservation{
+					ID: 3, WarehouseID: 2, ProductCode: "olkiuj", Count: 1000, Status: model.StatusReserved,
+					UserID: "lol", CreatedAt: createdAt,
+				}, nil)
+			},
+			expected: &DTO.Reservation{
+				ID: 3, WarehouseID: 2, UniqueCode: "olkiuj", Count: 1000, Status: model.StatusReserved,
+				Owner: "lol", CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "Non-existent reservation",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().GetReservation(3).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistReservationId,
+		},
+		{
+			name: "Repository error",
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().GetReservation(3).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			test.mockBehaviour(*productRepository)
+
+			s := NewProductService(productRepository, nil, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetReservation(&DTO.ReqGetReservation{ID: 3})
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestProductService_ListReservations(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository)
+
+	// page returns reservations with the given IDs as the repository lists them.
+	page := func(ids ...int) []model.Reservation {
+		reservations := make([]model.Reservation, 0, len(ids))
+		for _, id := range ids {
+			reservations = append(reservations, model.Reservation{ID: id, WarehouseID: 2, ProductCode: "olkiuj"})
+		}
+		return reservations
+	}
+	dtos := func(ids ...int) []DTO.Reservation {
+		reservations := make([]DTO.Reservation, 0, len(ids))
+		for _, id := range ids {
+			reservations = append(reservations, DTO.Reservation{ID: id, WarehouseID: 2, UniqueCode: "olkiuj"})
+		}
+		return reservations
+	}
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqListReservations
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResListReservations
+		expectedErr   error
+	}{
+		{
+			name: "Full page",
+			req:  DTO.ReqListReservations{WarehouseID: 2, Status: model.StatusReserved, Cursor: 4, Limit: 3},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ListReservations(&model.ReservationFilter{
+					WarehouseID: 2, Status: model.StatusReserved, Cursor: 4, Limit: 3,
+				}).Return(page(5, 7, 8), nil)
+			},
+			expected: &DTO.ResListReservations{Reservations: dtos(5, 7, 8), NextCursor: 8},
+		},
+		{
+			name: "Last page",
+			req:  DTO.ReqListReservations{Cursor: 8, Limit: 3},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ListReservations(&model.ReservationFilter{Cursor: 8, Limit: 3}).Return(page(9, 12), nil)
+			},
+			expected: &DTO.ResListReservations{Reservations: dtos(9, 12)},
+		},
+		{
+			name: "Empty page",
+			req:  DTO.ReqListReservations{Cursor: 12, Owner: "lol", UniqueCode: "olkiuj"},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ListReservations(&model.ReservationFilter{
+					ProductCode: "olkiuj", UserID: "lol", Cursor: 12, Limit: defaultPageSize,
+				}).Return(page(), nil)
+			},
+			expected: &DTO.ResListReservations{Reservations: dtos()},
+		},
+		{
+			name:          "Invalid status",
+			req:           DTO.ReqListReservations{Status: "lost"},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {},
+			expectedErr:   ErrInvalidStatus,
+		},
+		{
+			name:          "Too large limit",
+			req:           DTO.ReqListReservations{Limit: maxPageSize + 1},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {},
+			expectedErr:   ErrInvalidLimit,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqListReservations{},
+			mockBehaviour: func(p mock_repository.MockProductRepository) {
+				p.EXPECT().ListReservations(gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			test.mockBehaviour(*productRepository)
+
+			s := NewProductService(productRepository, nil, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.ListReservations(&test.req)
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
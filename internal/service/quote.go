@@ -0,0 +1,129 @@
+package service
+
+import (
+	"example1/internal/DTO"
+)
+
+// Quote tells whether the reservation request could be carried out now without reserving
+// anything. It runs the same checks as Reserve against a snapshot of the stock and reports
+// for every line whether it would be reserved or backordered and how much of the product
+// would be left free after the whole request.
+func (s *productService) Quote(req *DTO.ReqReserveProduct) (*DTO.ResQuoteReservation, error) {
+	s.Logger.Info("start service Quote")
+
+	if err := validateReserveRequest(req); err != nil {
+		return nil, err
+	}
+	if req.Split {
+		return s.quoteSplit(req)
+	}
+
+	available, err := s.WarehouseRepository.CheckAvailable(req.WarehouseID)
+	if err != nil {
+		return nil, ErrInvalidWarehouse
+	}
+	if available == false {
+		return nil, ErrWarehouseUnavailable
+	}
+
+	items, err := s.WarehouseRepository.Availability(req.UniqueCodes)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	left := make(map[string]int)
+	for _, item := range items {
+		if item.WarehouseID == req.WarehouseID {
+			left[item.ProductCode] = item.LeftCount
+		}
+	}
+
+	lines := make([]DTO.QuoteLine, len(req.UniqueCodes))
+	errs := make([]error, len(req.UniqueCodes))
+	taken := make(map[string]int)
+	for i, code := range req.UniqueCodes {
+		lines[i] = DTO.QuoteLine{UniqueCode: code, Count: req.Counts[i]}
+		stock, ok := left[code]
+		switch {
+		case req.Counts[i] <= 0:
+			errs[i] = ErrInvalidCount
+		case !ok:
+			errs[i] = ErrInvalidUniqueCode
+		case stock-taken[code] < req.Counts[i]:
+			if req.Backorder {
+				// The stock that is left would be reserved and only the shortfall backordered.
+				taken[code] = max(taken[code], stock)
+				lines[i].Backordered = true
+				continue
+			}
+			errs[i] = ErrNotEnoughProduct
+		default:
+			taken[code] += req.Counts[i]
+			lines[i].WouldSucceed = true
+		}
+	}
+
+	result, rolledBack := quoteResult(lines, errs, req.Atomic)
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		line.Remaining = left[line.UniqueCode]
+		if !rolledBack {
+			line.Remaining -= taken[line.UniqueCode]
+		}
+	}
+	return result, nil
+}
+
+// quoteSplit quotes a reservation split across the available warehouses. The remaining count
+// of a line is the free stock of the product in all of them.
+func (s *productService) quoteSplit(req *DTO.ReqReserveProduct) (*DTO.ResQuoteReservation, error) {
+	plan, errs, stock, err := s.planLines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]DTO.QuoteLine, len(req.UniqueCodes))
+	taken := make(map[string]int)
+	for i, code := range req.UniqueCodes {
+		lines[i] = DTO.QuoteLine{UniqueCode: code, Count: req.Counts[i], WouldSucceed: errs[i] == nil}
+		for _, chunk := range plan[i] {
+			lines[i].Chunks = append(lines[i].Chunks, DTO.QuoteChunk{WarehouseID: chunk.WarehouseID, Count: chunk.Count})
+			taken[code] += chunk.Count
+		}
+	}
+
+	result, rolledBack := quoteResult(lines, errs, req.Atomic)
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		for _, item := range stock[line.UniqueCode] {
+			line.Remaining += item.LeftCount
+		}
+		if rolledBack {
+			line.Remaining += taken[line.UniqueCode]
+			line.Chunks = nil
+		}
+	}
+	return result, nil
+}
+
+// quoteResult fills in the errors of the lines. As in Reserve, when an atomic request has a
+// failing line, the lines that could have been reserved are reported as rolled back and nothing
+// would be reserved.
+func quoteResult(lines []DTO.QuoteLine, errs []error, atomic bool) (*DTO.ResQuoteReservation, bool) {
+	result := &DTO.ResQuoteReservation{Reservable: true, Lines: lines}
+	for i, err := range errs {
+		if err != nil {
+			lines[i].Error = err.Error()
+			result.Reservable = false
+		}
+	}
+	if !atomic || result.Reservable {
+		return result, false
+	}
+	for i := range lines {
+		if lines[i].WouldSucceed {
+			lines[i].WouldSucceed = false
+			lines[i].Error = ErrRolledBack.Error()
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestQuoteResult(t *testing.T) {
+	testTable := []struct {
+		name               string
+		lines              []DTO.QuoteLine
+		errs               []error
+		atomic             bool
+		expectedLines      []DTO.QuoteLine
+		expectedReservable bool
+		expectedRolledBack bool
+	}{
+		{
+			name:               "OK",
+			lines:              []DTO.QuoteLine{{UniqueCode: "olkiuj", Count: 5, WouldSucceed: true}},
+			errs:               []error{nil},
+			atomic:             true,
+			expectedLines:      []DTO.QuoteLine{{UniqueCode: "olkiuj", Count: 5, WouldSucceed: true}},
+			expectedReservable: true,
+		},
+		{
+			name: "Partial",
+			lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 5, WouldSucceed: true},
+				{UniqueCode: "tghyuj", Count: 1200},
+			},
+			errs: []error{nil, ErrNotEnoughProduct},
+			expectedLines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 5, WouldSucceed: true},
+				{UniqueCode: "tghyuj", Count: 1200, Error: ErrNotEnoughProduct.Error()},
+			},
+		},
+		{
+			name: "Atomic rolled back",
+			lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 5, WouldSucceed: true},
+				{UniqueCode: "tghyuj", Count: 1200},
+			},
+			errs:   []error{nil, ErrNotEnoughProduct},
+			atomic: true,
+			expectedLines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 5, Error: ErrRolledBack.Error()},
+				{UniqueCode: "tghyuj", Count: 1200, Error: ErrNotEnoughProduct.Error()},
+			},
+			expectedRolledBack: true,
+		},
+		{
+			name:               "Backordered",
+			lines:              []DTO.QuoteLine{{UniqueCode: "tghyuj", Count: 1200, Backordered: true}},
+			errs:               []error{nil},
+			expectedLines:      []DTO.QuoteLine{{UniqueCode: "tghyuj", Count: 1200, Backordered: true}},
+			expectedReservable: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			result, rolledBack := quoteResult(test.lines, test.errs, test.atomic)
+			assert.Equal(t, test.expectedLines, result.Lines)
+			assert.Equal(t, test.expectedReservable, result.Reservable)
+			assert.Equal(t, test.expectedRolledBack, rolledBack)
+		})
+	}
+}
+
+// The quote tests set no expectations on the product repository, so any reservation made by
+// Quote fails them.
+func TestProductService_Quote(t *testing.T) {
+	stock := []model.WarehouseProduct{
+		{WarehouseID: 2, ProductCode: "olkiuj", LeftCount: 100},
+		{WarehouseID: 3, ProductCode: "olkiuj", LeftCount: 500},
+		{WarehouseID: 2, ProductCode: "tghyuj", LeftCount: 20},
+	}
+
+	testTable := []struct {
+		name        string
+		req         DTO.ReqReserveProduct
+		available   bool
+		expected    *DTO.ResQuoteReservation
+		expectedErr error
+	}{
+		{
+			name: "Repeated code shares the stock",
+			req: DTO.ReqReserveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{"olkiuj", "tghyuj", "olkiuj", "olkiuj"},
+				Counts:      []int{60, 5, 50, 40},
+			},
+			available: true,
+			expected: &DTO.ResQuoteReservation{Lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 60, WouldSucceed: true},
+				{UniqueCode: "tghyuj", Count: 5, WouldSucceed: true, Remaining: 15},
+				{UniqueCode: "olkiuj", Count: 50, Error: ErrNotEnoughProduct.Error()},
+				{UniqueCode: "olkiuj", Count: 40, WouldSucceed: true},
+			}},
+		},
+		{
+			name: "Atomic rolled back",
+			req: DTO.ReqReserveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{"olkiuj", "tghyuj", "lkjhgf"},
+				Counts:      []int{60, 5, 1},
+				Atomic:      true,
+			},
+			available: true,
+			expected: &DTO.ResQuoteReservation{Lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 60, Remaining: 100, Error: ErrRolledBack.Error()},
+				{UniqueCode: "tghyuj", Count: 5, Remaining: 20, Error: ErrRolledBack.Error()},
+				{UniqueCode: "lkjhgf", Count: 1, Error: ErrInvalidUniqueCode.Error()},
+			}},
+		},
+		{
+			name: "Backorder",
+			req: DTO.ReqReserveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{"tghyuj", "olkiuj", "olkiuj"},
+				Counts:      []int{5, 70, 50},
+				Backorder:   true,
+			},
+			available: true,
+			expected: &DTO.ResQuoteReservation{Reservable: true, Lines: []DTO.QuoteLine{
+				{UniqueCode: "tghyuj", Count: 5, WouldSucceed: true, Remaining: 15},
+				{UniqueCode: "olkiuj", Count: 70, WouldSucceed: true},
+				{UniqueCode: "olkiuj", Count: 50, Backordered: true},
+			}},
+		},
+		{
+			name:        "Warehouse unavailable",
+			req:         DTO.ReqReserveProduct{WarehouseID: 2, UniqueCodes: []string{"olkiuj"}, Counts: []int{1}},
+			available:   false,
+			expectedErr: ErrWarehouseUnavailable,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			warehouseRepository := mock_repository.NewMockWarehouseRepository(ctrl)
+			warehouseRepository.EXPECT().CheckAvailable(2).Return(test.available, nil)
+			if test.available {
+				warehouseRepository.EXPECT().Availability(test.req.UniqueCodes).Return(stock, nil)
+			}
+
+			s := NewProductService(productRepository, warehouseRepository, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Quote(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestProductService_QuoteSplit(t *testing.T) {
+	stock := []model.WarehouseProduct{
+		{WarehouseID: 1, ProductCode: "olkiuj", LeftCount: 100},
+		{WarehouseID: 2, ProductCode: "olkiuj", LeftCount: 400},
+		{WarehouseID: 1, ProductCode: "tghyuj", LeftCount: 50},
+	}
+
+	testTable := []struct {
+		name     string
+		counts   []int
+		atomic   bool
+		expected *DTO.ResQuoteReservation
+	}{
+		{
+			name:   "Repeated code shares the stock",
+			counts: []int{450, 50, 40},
+			expected: &DTO.ResQuoteReservation{Reservable: true, Lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 450, WouldSucceed: true, Remaining: 10,
+					Chunks: []DTO.QuoteChunk{{WarehouseID: 2, Count: 400}, {WarehouseID: 1, Count: 50}}},
+				{UniqueCode: "tghyuj", Count: 50, WouldSucceed: true,
+					Chunks: []DTO.QuoteChunk{{WarehouseID: 1, Count: 50}}},
+				{UniqueCode: "olkiuj", Count: 40, WouldSucceed: true, Remaining: 10,
+					Chunks: []DTO.QuoteChunk{{WarehouseID: 1, Count: 40}}},
+			}},
+		},
+		{
+			name:   "Atomic rolled back",
+			counts: []int{450, 60, 40},
+			atomic: true,
+			expected: &DTO.ResQuoteReservation{Lines: []DTO.QuoteLine{
+				{UniqueCode: "olkiuj", Count: 450, Remaining: 500, Error: ErrRolledBack.Error()},
+				{UniqueCode: "tghyuj", Count: 60, Remaining: 50, Error: ErrNotEnoughProduct.Error()},
+				{UniqueCode: "olkiuj", Count: 40, Remaining: 500, Error: ErrRolledBack.Error()},
+			}},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			warehouseRepository := mock_repository.NewMockWarehouseRepository(ctrl)
+			warehouseRepository.EXPECT().Availability([]string{"olkiuj", "tghyuj", "olkiuj"}).Return(stock, nil)
+
+			s := NewProductService(productRepository, warehouseRepository, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Quote(&DTO.ReqReserveProduct{
+				UniqueCodes: []string{"olkiuj", "tghyuj", "olkiuj"},
+				Counts:      test.counts,
+				Atomic:      test.atomic,
+				Split:       true,
+				Strategy:    splitMostStock,
+			})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"example1/internal/DTO"
+)
+
+// Reconcile compares left_count of every product in every warehouse with total_count minus the
+// count held by its live reservations and reports the rows that differ. With req.Fix set,
+// left_count is corrected to the expected count and the correction is recorded in the stock
+// ledger with the reconcile reason. Rows whose live reservations exceed total_count cannot be
+// corrected this way and are only reported.
+func (s *warehouseService) Reconcile(req *DTO.ReqReconcile, user *AuthInfo) (*DTO.ResReconcile, error) {
+	s.Logger.Info("start service Reconcile")
+
+	discrepancies, err := s.Repository.Discrepancies()
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResReconcile{Discrepancies: make([]DTO.Discrepancy, 0, len(discrepancies))}
+	for i := range discrepancies {
+		d := &discrepancies[i]
+		if req.Fix {
+			if err = s.Repository.FixDiscrepancy(d, user.ID); err != nil {
+				return nil, ErrInternal
+			}
+			if d.Fixed && d.Difference() < 0 {
+				s.Alerts.dropped(d.WarehouseID, d.ProductCode, -d.Difference())
+			}
+		}
+
+		res.Discrepancies = append(res.Discrepancies, DTO.Discrepancy{
+			WarehouseID: d.WarehouseID,
+			UniqueCode:  d.ProductCode,
+			TotalCount:  d.TotalCount,
+			LeftCount:   d.LeftCount,
+			Reserved:    d.Reserved,
+			Expected:    d.Expected,
+			Fixed:       d.Fixed,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestWarehouseService_Reconcile(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	// The expected left_count is total_count minus the reserved and confirmed reservations:
+	// 100 - (20 + 10) for olkiuj, 50 - 0 for tghyuj and 10 - 15 for lkjhgf.
+	discrepancies := func() []model.Discrepancy {
+		return []model.Discrepancy{
+			{WarehouseID: 2, ProductCode: "olkiuj", TotalCount: 100, LeftCount: 90, Reserved: 30, Expected: 70},
+			{WarehouseID: 2, ProductCode: "tghyuj", TotalCount: 50, LeftCount: 40, Expected: 50},
+			{WarehouseID: 3, ProductCode: "lkjhgf", TotalCount: 10, Reserved: 15, Expected: -5},
+		}
+	}
+	// fix mimics the repository, which corrects left_count unless the reservations exceed total_count.
+	fix := func(d *model.Discrepancy, userID string) error {
+		if d.Expected >= 0 {
+			d.Fixed = true
+		}
+		return nil
+	}
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqReconcile
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResReconcile
+		expectedErr   error
+	}{
+		{
+			name: "Report only",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return(discrepancies(), nil)
+			},
+			expected: &DTO.ResReconcile{Discrepancies: []DTO.Discrepancy{
+				{WarehouseID: 2, UniqueCode: "olkiuj", TotalCount: 100, LeftCount: 90, Reserved: 30, Expected: 70},
+				{WarehouseID: 2, UniqueCode: "tghyuj", TotalCount: 50, LeftCount: 40, Expected: 50},
+				{WarehouseID: 3, UniqueCode: "lkjhgf", TotalCount: 10, Reserved: 15, Expected: -5},
+			}},
+		},
+		{
+			name: "Fix",
+			req:  DTO.ReqReconcile{Fix: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return(discrepancies(), nil)
+				r.EXPECT().FixDiscrepancy(gomock.Any(), "root").DoAndReturn(fix).Times(3)
+				r.EXPECT().GetStock(2, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.ResReconcile{Discrepancies: []DTO.Discrepancy{
+				{WarehouseID: 2, UniqueCode: "olkiuj", TotalCount: 100, LeftCount: 90, Reserved: 30, Expected: 70, Fixed: true},
+				{WarehouseID: 2, UniqueCode: "tghyuj", TotalCount: 50, LeftCount: 40, Expected: 50, Fixed: true},
+				{WarehouseID: 3, UniqueCode: "lkjhgf", TotalCount: 10, Reserved: 15, Expected: -5},
+			}},
+		},
+		{
+			name: "Fixed since reported",
+			req:  DTO.ReqReconcile{Fix: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return(discrepancies()[:1], nil)
+				r.EXPECT().FixDiscrepancy(gomock.Any(), "root").DoAndReturn(func(d *model.Discrepancy, userID string) error {
+					d.LeftCount = d.Expected
+					return nil
+				})
+			},
+			expected: &DTO.ResReconcile{Discrepancies: []DTO.Discrepancy{
+				{WarehouseID: 2, UniqueCode: "olkiuj", TotalCount: 100, LeftCount: 70, Reserved: 30, Expected: 70},
+			}},
+		},
+		{
+			name: "Nothing to reconcile",
+			req:  DTO.ReqReconcile{Fix: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return([]model.Discrepancy{}, nil)
+			},
+			expected: &DTO.ResReconcile{Discrepancies: []DTO.Discrepancy{}},
+		},
+		{
+			name: "Fix failed",
+			req:  DTO.ReqReconcile{Fix: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return(discrepancies(), nil)
+				r.EXPECT().FixDiscrepancy(gomock.Any(), "root").Return(errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+		{
+			name: "Repository error",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Discrepancies().Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Reconcile(&test.req, &AuthInfo{ID: "root", Role: admin})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+)
+
+var ErrSerialCountMismatch = errors.New("serial numbers do not match the count")
+var ErrNotSerialized = errors.New("product is not serialized")
+var ErrSerialExists = errors.New("serial number is already registered")
+var ErrNonExistSerialNumber = errors.New("non-existent serial number")
+var ErrSerialNotAvailable = errors.New("serial number is not available in the warehouse")
+
+// GetSerialNumber shows where the units with the requested serial number are and everything
+// that happened to them. Serial numbers are unique per product, so without a unique code the
+// units of several products may be returned.
+func (s *warehouseService) GetSerialNumber(req *DTO.ReqGetSerialNumber) (*DTO.ResGetSerialNumber, error) {
+	s.Logger.Info("start service GetSerialNumber")
+
+	serials, err := s.Repository.SerialNumbers(req.SerialNumber, req.UniqueCode)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if len(serials) == 0 {
+		return nil, ErrNonExistSerialNumber
+	}
+
+	res := &DTO.ResGetSerialNumber{SerialNumbers: make([]DTO.SerialNumber, 0, len(serials))}
+	for i := range serials {
+		res.SerialNumbers = append(res.SerialNumbers, toSerialNumberDTO(&serials[i]))
+	}
+	return res, nil
+}
+
+// receiptSerials returns the serial numbers of the units received on the i-th line of the receipt.
+func receiptSerials(req *DTO.ReqReceiveProduct, i int) []string {
+	if i < len(req.SerialNumbers) {
+		return req.SerialNumbers[i]
+	}
+	return nil
+}
+
+func toSerialNumberDTO(serial *model.SerialNumber) DTO.SerialNumber {
+	res := DTO.SerialNumber{
+		SerialNumber:  serial.SerialNumber,
+		UniqueCode:    serial.ProductCode,
+		WarehouseID:   serial.WarehouseID,
+		Status:        serial.Status,
+		ReservationID: serial.ReservationID,
+		TransferID:    serial.TransferID,
+		History:       make([]DTO.SerialEvent, 0, len(serial.History)),
+	}
+	for _, event := range serial.History {
+		res.History = append(res.History, DTO.SerialEvent{
+			Status:        event.Status,
+			WarehouseID:   event.WarehouseID,
+			ReservationID: event.ReservationID,
+			TransferID:    event.TransferID,
+			UserID:        event.UserID,
+			CreatedAt:     event.CreatedAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestWarehouseService_Receive_Serials(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	receipt := func(code string, count int, serials ...string) *model.Receipt {
+		return &model.Receipt{WarehouseID: 2, ProductCode: code, Count: count, SerialNumbers: serials, UserID: "lol"}
+	}
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqReceiveProduct
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResReceiveProduct
+	}{
+		{
+			name: "Serial number for every unit",
+			req: DTO.ReqReceiveProduct{
+				WarehouseID:   2,
+				UniqueCodes:   []string{"lkjhgf"},
+				Counts:        []int{2},
+				SerialNumbers: [][]string{{"SN-1", "SN-2"}},
+			},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReceiveProduct(receipt("lkjhgf", 2, "SN-1", "SN-2")).Return(
+					&model.WarehouseProduct{ProductCode: "lkjhgf", TotalCount: 2, LeftCount: 2}, nil, nil)
+			},
+			expected: &DTO.ResReceiveProduct{
+				Successful:   []DTO.Received{{UniqueCode: "lkjhgf", TotalCount: 2, LeftCount: 2}},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Count and serial numbers mismatch",
+			req: DTO.ReqReceiveProduct{
+				WarehouseID:   2,
+				UniqueCodes:   []string{"lkjhgf", "lkjhgf"},
+				Counts:        []int{3, 1},
+				SerialNumbers: [][]string{{"SN-1", "SN-2"}},
+			},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReceiveProduct(receipt("lkjhgf", 3, "SN-1", "SN-2")).Return(
+					nil, nil, repository.ErrSerialCountMismatch)
+				r.EXPECT().ReceiveProduct(receipt("lkjhgf", 1)).Return(nil, nil, repository.ErrSerialCountMismatch)
+			},
+			expected: &DTO.ResReceiveProduct{
+				Successful:   []DTO.Received{},
+				Unsuccessful: []string{"lkjhgf", "lkjhgf"},
+				Errors:       []string{ErrSerialCountMismatch.Error(), ErrSerialCountMismatch.Error()},
+			},
+		},
+		{
+			name: "Serial numbers for a product that is not serialized",
+			req: DTO.ReqReceiveProduct{
+				WarehouseID:   2,
+				UniqueCodes:   []string{"olkiuj", "lkjhgf"},
+				Counts:        []int{1, 1},
+				SerialNumbers: [][]string{{"SN-1"}, {"SN-1"}},
+			},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ReceiveProduct(receipt("olkiuj", 1, "SN-1")).Return(nil, nil, repository.ErrNotSerialized)
+				r.EXPECT().ReceiveProduct(receipt("lkjhgf", 1, "SN-1")).Return(nil, nil, repository.ErrSerialExists)
+			},
+			expected: &DTO.ResReceiveProduct{
+				Successful:   []DTO.Received{},
+				Unsuccessful: []string{"olkiuj", "lkjhgf"},
+				Errors:       []string{ErrNotSerialized.Error(), ErrSerialExists.Error()},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			r.EXPECT().CheckAvailable(2).Return(true, nil)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Receive(&test.req, &AuthInfo{ID: "lol", Role: warehouseWorker})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_GetSerialNumber(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	testTable := []struct {
+		name          string
+		req           DTO.ReqGetSerialNumber
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResGetSerialNumber
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			req:  DTO.ReqGetSerialNumber{SerialNumber: "SN-1", UniqueCode: "lkjhgf"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().SerialNumbers("SN-1", "lkjhgf").Return([]model.SerialNumber{{
+					ProductCode: "lkjhgf", SerialNumber: "SN-1", WarehouseID: 2, Status: model.SerialAvailable,
+					History: []model.SerialEvent{{Status: model.SerialAvailable, WarehouseID: 2, UserID: "lol",
+						CreatedAt: createdAt}},
+				}}, nil)
+			},
+			expected: &DTO.ResGetSerialNumber{SerialNumbers: []DTO.SerialNumber{{
+				SerialNumber: "SN-1", UniqueCode: "lkjhgf", WarehouseID: 2, Status: model.SerialAvailable,
+				History: []DTO.SerialEvent{{Status: model.SerialAvailable, WarehouseID: 2, UserID: "lol",
+					CreatedAt: createdAt}},
+			}}},
+		},
+		{
+			name: "Non-existent serial number",
+			req:  DTO.ReqGetSerialNumber{SerialNumber: "SN-404"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().SerialNumbers("SN-404", "").Return([]model.SerialNumber{}, nil)
+			},
+			expectedErr: ErrNonExistSerialNumber,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqGetSerialNumber{SerialNumber: "SN-1"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().SerialNumbers("SN-1", "").Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetSerialNumber(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
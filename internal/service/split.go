@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"sort"
+)
+
+var ErrInvalidStrategy = errors.New("invalid split strategy")
+var ErrSplitBackorder = errors.New("backorder is not supported for split reservations")
+
+const (
+	splitPreferred    = "preferred"
+	splitFewestSplits = "fewest_splits"
+	splitMostStock    = "most_stock"
+)
+
+func isSplitStrategy(s string) bool {
+	switch s {
+	case splitPreferred, splitFewestSplits, splitMostStock:
+		return true
+	}
+	return false
+}
+
+// splitChunk is the part of a reservation line taken from one warehouse.
+type splitChunk struct {
+	WarehouseID int
+	Count       int
+}
+
+// reserveSplit reserves every line of the request across the available warehouses, making one
+// reservation per warehouse the line is split into. The chunks of a line are reserved in one
+// transaction, so a line is either reserved in full or not at all; with Atomic set, the same
+// holds for the whole request.
+func (s *productService) reserveSplit(req *DTO.ReqReserveProduct, user *AuthInfo,
+	result *DTO.ResReserveProduct) (*DTO.ResReserveProduct, error) {
+	plan, errs, _, err := s.planLines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.expiresAt(req.TTL)
+	lines := make([][]*model.Reservation, len(req.UniqueCodes))
+	for i, chunks := range plan {
+		for _, chunk := range chunks {
+			lines[i] = append(lines[i], &model.Reservation{
+				WarehouseID: chunk.WarehouseID,
+				ProductCode: req.UniqueCodes[i],
+				Count:       chunk.Count,
+				ExpiresAt:   expiresAt,
+				UserID:      user.ID,
+			})
+		}
+	}
+
+	if req.Atomic {
+		failed := false
+		for _, err := range errs {
+			if err != nil {
+				failed = true
+				break
+			}
+		}
+		if !failed {
+			var all []*model.Reservation
+			for _, chunks := range lines {
+				all = append(all, chunks...)
+			}
+			chunkErrs, err := s.ProductRepository.ReserveProducts(all)
+			if err != nil {
+				s.Logger.Error(err)
+				return nil, ErrInternal
+			}
+			k := 0
+			for i, chunks := range lines {
+				for range chunks {
+					if chunkErrs[k] != nil && errs[i] == nil {
+						errs[i] = lineError(chunkErrs[k])
+						failed = true
+					}
+					k++
+				}
+			}
+		}
+
+		for i, chunks := range lines {
+			switch {
+			case errs[i] != nil:
+				result.Unsuccessful = append(result.Unsuccessful, req.UniqueCodes[i])
+				result.Errors = append(result.Errors, errs[i].Error())
+			case failed:
+				result.Unsuccessful = append(result.Unsuccessful, req.UniqueCodes[i])
+				result.Errors = append(result.Errors, ErrRolledBack.Error())
+			default:
+				s.appendChunks(result, chunks)
+			}
+		}
+		return result, nil
+	}
+
+	for i, chunks := range lines {
+		if errs[i] == nil {
+			errs[i] = s.reserveChunks(chunks)
+		}
+		if errs[i] != nil {
+			result.Unsuccessful = append(result.Unsuccessful, req.UniqueCodes[i])
+			result.Errors = append(result.Errors, errs[i].Error())
+			continue
+		}
+		s.appendChunks(result, chunks)
+	}
+	return result, nil
+}
+
+// planLines splits every line of the request across the available warehouses. Lines for the
+// same product share its stock, which is returned as left after all the lines are taken.
+func (s *productService) planLines(req *DTO.ReqReserveProduct) ([][]splitChunk, []error,
+	map[string][]model.WarehouseProduct, error) {
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = s.Config.SplitStrategy
+	}
+	if !isSplitStrategy(strategy) {
+		return nil, nil, nil, ErrInvalidStrategy
+	}
+
+	items, err := s.WarehouseRepository.Availability(req.UniqueCodes)
+	if err != nil {
+		return nil, nil, nil, ErrInternal
+	}
+	stock := make(map[string][]model.WarehouseProduct)
+	for _, item := range items {
+		stock[item.ProductCode] = append(stock[item.ProductCode], item)
+	}
+
+	plan := make([][]splitChunk, len(req.UniqueCodes))
+	errs := make([]error, len(req.UniqueCodes))
+	for i, code := range req.UniqueCodes {
+		if req.Counts[i] <= 0 {
+			errs[i] = ErrInvalidCount
+			continue
+		}
+		chunks := planSplit(req.Counts[i], stock[code], req.PreferredWarehouseID, strategy)
+		if chunks == nil {
+			errs[i] = ErrNotEnoughProduct
+			continue
+		}
+		for _, chunk := range chunks {
+			for j := range stock[code] {
+				if stock[code][j].WarehouseID == chunk.WarehouseID {
+					stock[code][j].LeftCount -= chunk.Count
+				}
+			}
+		}
+		plan[i] = chunks
+	}
+	return plan, errs, stock, nil
+}
+
+// reserveChunks reserves all chunks of a line in one transaction and returns the error of the
+// first chunk that failed.
+func (s *productService) reserveChunks(chunks []*model.Reservation) error {
+	errs, err := s.ProductRepository.ReserveProducts(chunks)
+	if err != nil {
+		s.Logger.Error(err)
+		return ErrInternal
+	}
+	for _, err := range errs {
+		if err != nil {
+			return lineError(err)
+		}
+	}
+	return nil
+}
+
+func (s *productService) appendChunks(result *DTO.ResReserveProduct, chunks []*model.Reservation) {
+	for _, re := range chunks {
+		result.Successful = append(result.Successful, DTO.Successful{
+			ID:            re.ID,
+			UniqueCode:    re.ProductCode,
+			WarehouseID:   re.WarehouseID,
+			Count:         re.Count,
+			ExpiresAt:     re.ExpiresAt,
+			Lots:          toLotCountDTOs(re.Lots),
+			SerialNumbers: re.SerialNumbers,
+		})
+		s.Alerts.dropped(re.WarehouseID, re.ProductCode, re.Count)
+	}
+}
+
+// planSplit decides how much of count to take from each warehouse in stock. It returns nil if
+// the warehouses do not have enough together.
+//   - preferred takes as much as possible from the preferred warehouse, then from the others by
+//     most stock;
+//   - fewest_splits takes everything from one warehouse if possible, the preferred one or else
+//     the one with the least stock that is enough, and otherwise splits by most stock, which
+//     needs the fewest warehouses;
+//   - most_stock takes from the warehouses by most stock.
+//
+// Warehouses with equal stock are taken in ID order, the preferred one first.
+func planSplit(count int, stock []model.WarehouseProduct, preferred int, strategy string) []splitChunk {
+	candidates := make([]model.WarehouseProduct, 0, len(stock))
+	for _, item := range stock {
+		if item.LeftCount > 0 {
+			candidates = append(candidates, item)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		aPreferred, bPreferred := a.WarehouseID == preferred, b.WarehouseID == preferred
+		if strategy == splitPreferred && aPreferred != bPreferred {
+			return aPreferred
+		}
+		if a.LeftCount != b.LeftCount {
+			return a.LeftCount > b.LeftCount
+		}
+		if aPreferred != bPreferred {
+			return aPreferred
+		}
+		return a.WarehouseID < b.WarehouseID
+	})
+
+	if strategy == splitFewestSplits {
+		best := -1
+		for i, item := range candidates {
+			if item.LeftCount < count {
+				continue
+			}
+			if item.WarehouseID == preferred {
+				best = i
+				break
+			}
+			if best < 0 || item.LeftCount < candidates[best].LeftCount {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return []splitChunk{{WarehouseID: candidates[best].WarehouseID, Count: count}}
+		}
+	}
+
+	var chunks []splitChunk
+	for _, item := range candidates {
+		take := min(item.LeftCount, count)
+		chunks = append(chunks, splitChunk{WarehouseID: item.WarehouseID, Count: take})
+		count -= take
+		if count == 0 {
+			return chunks
+		}
+	}
+	return nil
+}
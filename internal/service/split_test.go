@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestPlanSplit(t *testing.T) {
+	stock := []model.WarehouseProduct{
+		{WarehouseID: 1, LeftCount: 100},
+		{WarehouseID: 2, LeftCount: 400},
+		{WarehouseID: 3, LeftCount: 250},
+		{WarehouseID: 4, LeftCount: 0},
+	}
+
+	testTable := []struct {
+		name      string
+		count     int
+		preferred int
+		strategy  string
+		expected  []splitChunk
+	}{
+		{
+			name:      "Preferred first",
+			count:     500,
+			preferred: 1,
+			strategy:  splitPreferred,
+			expected:  []splitChunk{{WarehouseID: 1, Count: 100}, {WarehouseID: 2, Count: 400}},
+		},
+		{
+			name:      "Preferred covers the line",
+			count:     50,
+			preferred: 3,
+			strategy:  splitPreferred,
+			expected:  []splitChunk{{WarehouseID: 3, Count: 50}},
+		},
+		{
+			name:     "Fewest splits - one warehouse with the least enough stock",
+			count:    200,
+			strategy: splitFewestSplits,
+			expected: []splitChunk{{WarehouseID: 3, Count: 200}},
+		},
+		{
+			name:      "Fewest splits - preferred warehouse is enough",
+			count:     200,
+			preferred: 2,
+			strategy:  splitFewestSplits,
+			expected:  []splitChunk{{WarehouseID: 2, Count: 200}},
+		},
+		{
+			name:     "Fewest splits - split by most stock",
+			count:    600,
+			strategy: splitFewestSplits,
+			expected: []splitChunk{{WarehouseID: 2, Count: 400}, {WarehouseID: 3, Count: 200}},
+		},
+		{
+			name:      "Most stock",
+			count:     700,
+			preferred: 1,
+			strategy:  splitMostStock,
+			expected:  []splitChunk{{WarehouseID: 2, Count: 400}, {WarehouseID: 3, Count: 250}, {WarehouseID: 1, Count: 50}},
+		},
+		{
+			name:     "Not enough product",
+			count:    751,
+			strategy: splitMostStock,
+			expected: nil,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, planSplit(test.count, stock, test.preferred, test.strategy))
+		})
+	}
+}
+
+func TestProductService_Reserve_Split(t *testing.T) {
+	type mockBehaviour func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository)
+
+	stock := []model.WarehouseProduct{
+		{WarehouseID: 1, ProductCode: "olkiuj", LeftCount: 100},
+		{WarehouseID: 2, ProductCode: "olkiuj", LeftCount: 400},
+		{WarehouseID: 1, ProductCode: "tghyuj", LeftCount: 50},
+	}
+	// reserved mimics the repository: the chunks get IDs from 10 on and errs are returned as is.
+	reserved := func(errs ...error) func(chunks []*model.Reservation) ([]error, error) {
+		return func(chunks []*model.Reservation) ([]error, error) {
+			res := make([]error, len(chunks))
+			for i, re := range chunks {
+				if i < len(errs) {
+					res[i] = errs[i]
+				}
+				re.ID = 10 + i
+			}
+			return res, nil
+		}
+	}
+	chunk := func(warehouseID int, code string, count int) *model.Reservation {
+		return &model.Reservation{WarehouseID: warehouseID, ProductCode: code, Count: count, UserID: "lol"}
+	}
+
+	testTable := []struct {
+		name          string
+		atomic        bool
+		counts        []int
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResReserveProduct
+		expectedErr   error
+	}{
+		{
+			name:   "Atomic",
+			atomic: true,
+			counts: []int{450, 50},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().Availability([]string{"olkiuj", "tghyuj"}).Return(stock, nil)
+				p.EXPECT().ReserveProducts([]*model.Reservation{
+					chunk(2, "olkiuj", 400), chunk(1, "olkiuj", 50), chunk(1, "tghyuj", 50),
+				}).DoAndReturn(reserved())
+				w.EXPECT().GetStock(gomock.Any(), gomock.Any()).Return(&model.WarehouseProduct{}, nil).Times(3)
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful: []DTO.Successful{
+					{ID: 10, UniqueCode: "olkiuj", WarehouseID: 2, Count: 400},
+					{ID: 11, UniqueCode: "olkiuj", WarehouseID: 1, Count: 50},
+					{ID: 12, UniqueCode: "tghyuj", WarehouseID: 1, Count: 50},
+				},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name:   "Atomic - a chunk failed, the other lines rolled back",
+			atomic: true,
+			counts: []int{450, 50},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().Availability(gomock.Any()).Return(stock, nil)
+				p.EXPECT().ReserveProducts(gomock.Len(3)).DoAndReturn(reserved(nil, nil, repository.ErrNotEnoughLeft))
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful:   []DTO.Successful{},
+				Unsuccessful: []string{"olkiuj", "tghyuj"},
+				Errors:       []string{ErrRolledBack.Error(), ErrNotEnoughProduct.Error()},
+			},
+		},
+		{
+			name:   "Atomic - a line cannot be planned, nothing is reserved",
+			atomic: true,
+			counts: []int{450, 60},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().Availability(gomock.Any()).Return(stock, nil)
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful:   []DTO.Successful{},
+				Unsuccessful: []string{"olkiuj", "tghyuj"},
+				Errors:       []string{ErrRolledBack.Error(), ErrNotEnoughProduct.Error()},
+			},
+		},
+		{
+			name:   "Atomic - repository error",
+			atomic: true,
+			counts: []int{450, 50},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().Availability(gomock.Any()).Return(stock, nil)
+				p.EXPECT().ReserveProducts(gomock.Len(3)).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+		{
+			name:   "Lines reserved separately",
+			counts: []int{450, 50},
+			mockBehaviour: func(p mock_repository.MockProductRepository, w mock_repository.MockWarehouseRepository) {
+				w.EXPECT().Availability(gomock.Any()).Return(stock, nil)
+				p.EXPECT().ReserveProducts([]*model.Reservation{chunk(2, "olkiuj", 400), chunk(1, "olkiuj", 50)}).
+					DoAndReturn(reserved(nil, repository.ErrNotEnoughLeft))
+				p.EXPECT().ReserveProducts([]*model.Reservation{chunk(1, "tghyuj", 50)}).DoAndReturn(reserved())
+				w.EXPECT().GetStock(1, "tghyuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.ResReserveProduct{
+				Successful:   []DTO.Successful{{ID: 10, UniqueCode: "tghyuj", WarehouseID: 1, Count: 50}},
+				Unsuccessful: []string{"olkiuj"},
+				Errors:       []string{ErrNotEnoughProduct.Error()},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepository := mock_repository.NewMockProductRepository(ctrl)
+			warehouseRepository := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*productRepository, *warehouseRepository)
+
+			s := NewProductService(productRepository, warehouseRepository, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Reserve(&DTO.ReqReserveProduct{
+				UniqueCodes: []string{"olkiuj", "tghyuj"},
+				Counts:      test.counts,
+				Atomic:      test.atomic,
+				Split:       true,
+				Strategy:    splitMostStock,
+			}, &AuthInfo{ID: "lol", Role: productWorker})
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
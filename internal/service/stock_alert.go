@@ -0,0 +1,46 @@
+package service
+
+import (
+	"example1/internal/repository"
+	"example1/pkg/logger"
+	"example1/pkg/notifier"
+	"time"
+)
+
+// stockAlerts emits low-stock events when the free stock of a product drops below its reorder threshold.
+type stockAlerts struct {
+	Repository repository.WarehouseRepository
+	Notifier   notifier.Notifier
+	Logger     logger.Logger
+}
+
+func newStockAlerts(r repository.WarehouseRepository, n notifier.Notifier) *stockAlerts {
+	return &stockAlerts{r, n, logger.Get()}
+}
+
+// dropped is called after left_count of a product was decreased by delta. If that took it below
+// the reorder threshold, an event is sent in the background.
+func (a *stockAlerts) dropped(warehouseID int, productCode string, delta int) {
+	item, err := a.Repository.GetStock(warehouseID, productCode)
+	if err != nil {
+		a.Logger.Error(err)
+		return
+	}
+
+	if !item.BelowThreshold() || item.LeftCount+delta < item.ReorderThreshold {
+		return
+	}
+
+	event := notifier.LowStockEvent{
+		WarehouseID: item.WarehouseID,
+		ProductCode: item.ProductCode,
+		LeftCount:   item.LeftCount,
+		Threshold:   item.ReorderThreshold,
+		Time:        time.Now(),
+	}
+	go func() {
+		if err := a.Notifier.NotifyLowStock(event); err != nil {
+			a.Logger.Error(err)
+		}
+	}()
+}
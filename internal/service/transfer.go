@@ -0,0 +1,109 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+)
+
+var ErrSameWarehouse = errors.New("source and destination warehouses are the same")
+var ErrNonExistTransferId = errors.New("non-existent transfer id")
+var ErrTransferReceived = errors.New("transfer already received")
+
+// Transfer moves stock of a product from one warehouse to another. Both warehouses must be
+// available. With InTransit set, the stock leaves the source warehouse now and is added to the
+// destination by ReceiveTransfer.
+func (s *warehouseService) Transfer(req *DTO.ReqTransferProduct, user *AuthInfo) (*DTO.Transfer, error) {
+	s.Logger.Info("start service Transfer")
+
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, ErrSameWarehouse
+	}
+	if req.Count <= 0 {
+		return nil, ErrInvalidCount
+	}
+	for _, warehouseID := range []int{req.FromWarehouseID, req.ToWarehouseID} {
+		if err := s.checkAvailable(warehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer := &model.Transfer{
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		ProductCode:     req.UniqueCode,
+		Count:           req.Count,
+		CreatedBy:       user.ID,
+	}
+	err := s.Repository.TransferProduct(transfer, req.InTransit)
+	if err != nil {
+		return nil, lineError(err)
+	}
+	s.Alerts.dropped(transfer.FromWarehouseID, transfer.ProductCode, transfer.Count)
+
+	return toTransferDTO(transfer), nil
+}
+
+// ReceiveTransfer adds the stock of an in-transit transfer to its destination warehouse.
+func (s *warehouseService) ReceiveTransfer(req *DTO.ReqReceiveTransfer, user *AuthInfo) (*DTO.Transfer, error) {
+	s.Logger.Info("start service ReceiveTransfer")
+
+	transfer, err := s.Repository.GetTransfer(req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNonExistTransferId
+		}
+		return nil, ErrInternal
+	}
+	if err = s.checkAvailable(transfer.ToWarehouseID); err != nil {
+		return nil, err
+	}
+
+	transfer, err = s.Repository.ReceiveTransfer(req.ID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNonExistTransferId
+		case errors.Is(err, repository.ErrTransferReceived):
+			return nil, ErrTransferReceived
+		case errors.Is(err, repository.ErrLotExpiryMismatch):
+			return nil, ErrLotExpiryMismatch
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	return toTransferDTO(transfer), nil
+}
+
+// checkAvailable returns an error if the warehouse does not exist or is unavailable.
+func (s *warehouseService) checkAvailable(warehouseID int) error {
+	available, err := s.Repository.CheckAvailable(warehouseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidWarehouse
+		}
+		return ErrInternal
+	}
+	if available == false {
+		return ErrWarehouseUnavailable
+	}
+	return nil
+}
+
+func toTransferDTO(transfer *model.Transfer) *DTO.Transfer {
+	return &DTO.Transfer{
+		ID:              transfer.ID,
+		FromWarehouseID: transfer.FromWarehouseID,
+		ToWarehouseID:   transfer.ToWarehouseID,
+		UniqueCode:      transfer.ProductCode,
+		Count:           transfer.Count,
+		Status:          transfer.Status,
+		CreatedAt:       transfer.CreatedAt,
+		ReceivedAt:      transfer.ReceivedAt,
+		Lots:            toTransferLotDTOs(transfer.Lots),
+		SerialNumbers:   transfer.SerialNumbers,
+	}
+}
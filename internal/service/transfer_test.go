@@ -0,0 +1,153 @@
+package service
+
+import (
+	"database/sql"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestWarehouseService_Transfer(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqTransferProduct
+		mockBehaviour mockBehaviour
+		expected      *DTO.Transfer
+		expectedErr   error
+	}{
+		{
+			name: "In transit",
+			req:  DTO.ReqTransferProduct{FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10, InTransit: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(1).Return(true, nil)
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().TransferProduct(&model.Transfer{
+					FromWarehouseID: 1, ToWarehouseID: 2, ProductCode: "olkiuj", Count: 10, CreatedBy: "lol",
+				}, true).DoAndReturn(func(transfer *model.Transfer, inTransit bool) error {
+					transfer.ID = 3
+					transfer.Status = model.TransferInTransit
+					return nil
+				})
+				r.EXPECT().GetStock(1, "olkiuj").Return(&model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.Transfer{
+				ID: 3, FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10, Status: model.TransferInTransit,
+			},
+		},
+		{
+			name: "Destination capacity exceeded",
+			req:  DTO.ReqTransferProduct{FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10, InTransit: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(1).Return(true, nil)
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().TransferProduct(gomock.Any(), true).Return(repository.ErrCapacityExceeded)
+			},
+			expectedErr: ErrCapacityExceeded,
+		},
+		{
+			name: "Not enough left",
+			req:  DTO.ReqTransferProduct{FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(1).Return(true, nil)
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().TransferProduct(gomock.Any(), false).Return(repository.ErrNotEnoughLeft)
+			},
+			expectedErr: ErrNotEnoughProduct,
+		},
+		{
+			name:          "Same warehouse",
+			req:           DTO.ReqTransferProduct{FromWarehouseID: 1, ToWarehouseID: 1, UniqueCode: "olkiuj", Count: 10},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrSameWarehouse,
+		},
+		{
+			name: "Destination unavailable",
+			req:  DTO.ReqTransferProduct{FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(1).Return(true, nil)
+				r.EXPECT().CheckAvailable(2).Return(false, nil)
+			},
+			expectedErr: ErrWarehouseUnavailable,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Transfer(&test.req, &AuthInfo{ID: "lol", Role: warehouseWorker})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_ReceiveTransfer(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	transfer := &model.Transfer{ID: 3, FromWarehouseID: 1, ToWarehouseID: 2, ProductCode: "olkiuj", Count: 10,
+		Status: model.TransferInTransit}
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		expected      *DTO.Transfer
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetTransfer(3).Return(transfer, nil)
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().ReceiveTransfer(3, "lol").Return(&model.Transfer{ID: 3, FromWarehouseID: 1, ToWarehouseID: 2,
+					ProductCode: "olkiuj", Count: 10, Status: model.TransferReceived}, nil)
+			},
+			expected: &DTO.Transfer{
+				ID: 3, FromWarehouseID: 1, ToWarehouseID: 2, UniqueCode: "olkiuj", Count: 10, Status: model.TransferReceived,
+			},
+		},
+		{
+			name: "Already received",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetTransfer(3).Return(transfer, nil)
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().ReceiveTransfer(3, "lol").Return(nil, repository.ErrTransferReceived)
+			},
+			expectedErr: ErrTransferReceived,
+		},
+		{
+			name: "Non-existent transfer",
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().GetTransfer(3).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistTransferId,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.ReceiveTransfer(&DTO.ReqReceiveTransfer{ID: 3}, &AuthInfo{ID: "lol", Role: warehouseWorker})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
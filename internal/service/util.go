@@ -8,6 +8,21 @@ import (
 	"time"
 )
 
+const defaultPageSize = 50
+const maxPageSize = 500
+
+// pageSize returns the number of items to list for a requested limit, 0 meaning the default.
+func pageSize(limit int) (int, error) {
+	switch {
+	case limit == 0:
+		return defaultPageSize, nil
+	case limit < 0 || limit > maxPageSize:
+		return 0, ErrInvalidLimit
+	default:
+		return limit, nil
+	}
+}
+
 func HashPassword(password []byte) ([]byte, error) {
 	hashpassword, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
 	if err != nil {
@@ -2,35 +2,495 @@ package service
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"example1/config"
 	"example1/internal/DTO"
+	"example1/internal/model"
 	"example1/internal/repository"
 	"example1/pkg/logger"
+	"example1/pkg/notifier"
 )
 
 var ErrNoProducts = errors.New("no products")
+var ErrInvalidSort = errors.New("invalid sort")
+var ErrInvalidCursor = errors.New("invalid cursor")
+var ErrInvalidTimeRange = errors.New("invalid time range")
+var ErrInvalidThreshold = errors.New("invalid reorder threshold")
+var ErrNoStock = errors.New("product is not stocked in the warehouse")
+var ErrInvalidWarehouseName = errors.New("invalid warehouse name")
+var ErrWarehouseExists = errors.New("warehouse with this name already exists")
+var ErrNonExistWarehouse = errors.New("non-existent warehouse")
+var ErrReasonRequired = errors.New("reason is required")
+var ErrInvalidCapacity = errors.New("invalid capacity")
+var ErrCapacityExceeded = errors.New("warehouse capacity exceeded")
 
 type warehouseService struct {
 	Repository repository.WarehouseRepository
+	Config     config.Config
+	Alerts     *stockAlerts
 	Logger     logger.Logger
 }
 
-func NewWarehouseService(r repository.WarehouseRepository) WarehouseService {
-	return &warehouseService{r, logger.Get()}
+func NewWarehouseService(r repository.WarehouseRepository, c config.Config, n notifier.Notifier) WarehouseService {
+	return &warehouseService{r, c, newStockAlerts(r, n), logger.Get()}
 }
 
 type WarehouseService interface {
 	GetProducts(req *DTO.ReqGetProducts) (*DTO.ResGetProducts, error)
+	GetMovements(req *DTO.ReqGetMovements) (*DTO.ResGetMovements, error)
+	Receive(req *DTO.ReqReceiveProduct, user *AuthInfo) (*DTO.ResReceiveProduct, error)
+	Transfer(req *DTO.ReqTransferProduct, user *AuthInfo) (*DTO.Transfer, error)
+	ReceiveTransfer(req *DTO.ReqReceiveTransfer, user *AuthInfo) (*DTO.Transfer, error)
+	SetReorderThreshold(req *DTO.ReqSetReorderThreshold) (*DTO.StockLevel, error)
+	GetLowStock() (*DTO.ResGetLowStock, error)
+	Reconcile(req *DTO.ReqReconcile, user *AuthInfo) (*DTO.ResReconcile, error)
+	CreateWarehouse(req *DTO.ReqCreateWarehouse, user *AuthInfo) (*DTO.Warehouse, error)
+	UpdateWarehouse(req *DTO.ReqUpdateWarehouse) (*DTO.Warehouse, error)
+	GetWarehouse(req *DTO.ReqGetWarehouse) (*DTO.Warehouse, error)
+	ListWarehouses() (*DTO.ResListWarehouses, error)
+	SetAvailability(req *DTO.ReqSetWarehouseAvailability, user *AuthInfo) (*DTO.Warehouse, error)
+	GetUtilization() (*DTO.ResGetUtilization, error)
+	GetAvailability(req *DTO.ReqGetAvailability) (*DTO.ResGetAvailability, error)
+	GetExpiringLots(req *DTO.ReqGetExpiringLots) (*DTO.ResGetExpiringLots, error)
+	GetSerialNumber(req *DTO.ReqGetSerialNumber) (*DTO.ResGetSerialNumber, error)
+	AdjustStock(req *DTO.ReqAdjustStock, user *AuthInfo) (*DTO.Adjustment, error)
+	ApproveAdjustment(req *DTO.ReqReviewAdjustment, user *AuthInfo) (*DTO.Adjustment, error)
+	RejectAdjustment(req *DTO.ReqReviewAdjustment, user *AuthInfo) (*DTO.Adjustment, error)
+	ListAdjustments(req *DTO.ReqListAdjustments) (*DTO.ResListAdjustments, error)
+	OpenCycleCount(req *DTO.ReqOpenCycleCount, user *AuthInfo) (*DTO.CycleCount, error)
+	SubmitCycleCount(req *DTO.ReqSubmitCycleCount, user *AuthInfo) (*DTO.ResSubmitCycleCount, error)
+	GetCycleCount(req *DTO.ReqGetCycleCount) (*DTO.CycleCount, error)
+	CloseCycleCount(req *DTO.ReqCloseCycleCount, user *AuthInfo) (*DTO.CycleCount, error)
 }
 
+// GetProducts lists the products of a warehouse with their stock levels, filtered and sorted as
+// requested. NextCursor is set when there may be more of them and is passed as Cursor to get
+// the next page with the same filters and order.
 func (s *warehouseService) GetProducts(req *DTO.ReqGetProducts) (*DTO.ResGetProducts, error) {
 	s.Logger.Info("start service GetProducts")
-	products, err := s.Repository.AllProducts(req.WarehouseID)
-	if errors.Is(err, sql.ErrNoRows) || len(products) == 0 {
+
+	sort := req.Sort
+	if sort == "" {
+		sort = model.SortByCode
+	}
+	if !model.IsProductStockSort(sort) {
+		return nil, ErrInvalidSort
+	}
+	limit, err := pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &model.ProductStockFilter{
+		WarehouseID: req.WarehouseID,
+		CodePrefix:  req.CodePrefix,
+		Name:        req.Name,
+		InStock:     req.InStock,
+		Sort:        sort,
+		Desc:        req.Desc,
+		Limit:       limit,
+	}
+	if req.Cursor != "" {
+		filter.After, err = decodeProductCursor(req.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	products, err := s.Repository.AllProducts(filter)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if len(products) == 0 && req.Cursor == "" {
 		return nil, ErrNoProducts
 	}
+
+	res := &DTO.ResGetProducts{Products: make([]DTO.ProductStock, 0, len(products))}
+	for _, p := range products {
+		res.Products = append(res.Products, DTO.ProductStock{
+			UniqueCode: p.ProductCode,
+			Name:       p.Name,
+			Size:       p.Size,
+			TotalCount: p.TotalCount,
+			LeftCount:  p.LeftCount,
+			Reserved:   p.Reserved,
+		})
+	}
+	if len(products) == limit {
+		last := products[len(products)-1]
+		res.NextCursor = encodeProductCursor(&model.ProductStockKey{Value: last.SortValue(sort), ProductCode: last.ProductCode})
+	}
+	return res, nil
+}
+
+// encodeProductCursor encodes the position of a product in a sorted list as an opaque cursor.
+func encodeProductCursor(key *model.ProductStockKey) string {
+	data, _ := json.Marshal([]string{key.Value, key.ProductCode})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProductCursor(cursor string) (*model.ProductStockKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	key := []string{}
+	if err = json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	if len(key) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	return &model.ProductStockKey{Value: key[0], ProductCode: key[1]}, nil
+}
+
+// GetMovements lists the stock movements of a product in a warehouse in the requested time
+// range, oldest first. NextCursor is set when there may be more of them and is passed as
+// Cursor to get the next page.
+func (s *warehouseService) GetMovements(req *DTO.ReqGetMovements) (*DTO.ResGetMovements, error) {
+	s.Logger.Info("start service GetMovements")
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, ErrInvalidTimeRange
+	}
+	limit, err := pageSize(req.Limit)
 	if err != nil {
 		return nil, err
 	}
-	return &DTO.ResGetProducts{ProductsCodes: products}, nil
+
+	movements, err := s.Repository.ListMovements(&model.MovementFilter{
+		WarehouseID: req.WarehouseID,
+		ProductCode: req.UniqueCode,
+		From:        req.From,
+		To:          req.To,
+		Cursor:      req.Cursor,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResGetMovements{Movements: make([]DTO.StockMovement, 0, len(movements))}
+	for _, m := range movements {
+		res.Movements = append(res.Movements, DTO.StockMovement{
+			ID:            m.ID,
+			Delta:         m.Delta,
+			TotalDelta:    m.TotalDelta,
+			Reason:        m.Reason,
+			ReservationID: m.ReservationID,
+			UserID:        m.UserID,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	if len(movements) == limit {
+		res.NextCursor = movements[len(movements)-1].ID
+	}
+	return res, nil
+}
+
+// Receive adds received stock to a warehouse line by line. Each line is applied on its own,
+// so the result lists the lines that were received and the reasons the others failed.
+func (s *warehouseService) Receive(req *DTO.ReqReceiveProduct, user *AuthInfo) (*DTO.ResReceiveProduct, error) {
+	s.Logger.Info("start service Receive")
+
+	result := &DTO.ResReceiveProduct{
+		Successful:   make([]DTO.Received, 0),
+		Unsuccessful: make([]string, 0),
+		Errors:       make([]string, 0),
+	}
+
+	if err := s.checkAvailable(req.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(req.UniqueCodes); i++ {
+		lotNumber, expiresOn, err := receiptLot(req, i)
+		if err != nil {
+			result.Unsuccessful = append(result.Unsuccessful, req.UniqueCodes[i])
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+
+		item, lot, err := s.Repository.ReceiveProduct(&model.Receipt{
+			WarehouseID:   req.WarehouseID,
+			ProductCode:   req.UniqueCodes[i],
+			Count:         req.Counts[i],
+			LotNumber:     lotNumber,
+			ExpiresOn:     expiresOn,
+			SerialNumbers: receiptSerials(req, i),
+			UserID:        user.ID,
+		})
+		if err != nil {
+			result.Unsuccessful = append(result.Unsuccessful, req.UniqueCodes[i])
+			result.Errors = append(result.Errors, lineError(err).Error())
+			continue
+		}
+
+		received := DTO.Received{
+			UniqueCode: item.ProductCode,
+			TotalCount: item.TotalCount,
+			LeftCount:  item.LeftCount,
+		}
+		if lot != nil {
+			received.Lot = toLotDTO(lot)
+		}
+		result.Successful = append(result.Successful, received)
+	}
+
+	return result, nil
+}
+
+// SetReorderThreshold sets the free stock below which a low-stock event is emitted for a
+// product in a warehouse. Zero disables the alert.
+func (s *warehouseService) SetReorderThreshold(req *DTO.ReqSetReorderThreshold) (*DTO.StockLevel, error) {
+	s.Logger.Info("start service SetReorderThreshold")
+
+	if req.Threshold < 0 {
+		return nil, ErrInvalidThreshold
+	}
+
+	item, err := s.Repository.SetReorderThreshold(req.WarehouseID, req.UniqueCode, req.Threshold)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoStock
+		}
+		return nil, ErrInternal
+	}
+
+	res := toStockLevelDTO(item)
+	return &res, nil
+}
+
+// GetLowStock lists every product whose free stock is below its reorder threshold, across all warehouses.
+func (s *warehouseService) GetLowStock() (*DTO.ResGetLowStock, error) {
+	s.Logger.Info("start service GetLowStock")
+
+	items, err := s.Repository.LowStock()
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResGetLowStock{Items: make([]DTO.StockLevel, 0, len(items))}
+	for i := range items {
+		res.Items = append(res.Items, toStockLevelDTO(&items[i]))
+	}
+	return res, nil
+}
+
+func toStockLevelDTO(item *model.WarehouseProduct) DTO.StockLevel {
+	return DTO.StockLevel{
+		WarehouseID:      item.WarehouseID,
+		UniqueCode:       item.ProductCode,
+		TotalCount:       item.TotalCount,
+		LeftCount:        item.LeftCount,
+		ReorderThreshold: item.ReorderThreshold,
+	}
+}
+
+// CreateWarehouse adds a warehouse. Its initial availability is recorded in the availability
+// log as set by the user.
+func (s *warehouseService) CreateWarehouse(req *DTO.ReqCreateWarehouse, user *AuthInfo) (*DTO.Warehouse, error) {
+	s.Logger.Info("start service CreateWarehouse")
+
+	if req.Name == "" {
+		return nil, ErrInvalidWarehouseName
+	}
+	if req.Capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	warehouse := &model.Warehouse{Name: req.Name, Availability: req.Available, Capacity: req.Capacity}
+	err := s.Repository.CreateWarehouse(warehouse, user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWarehouseExists) {
+			return nil, ErrWarehouseExists
+		}
+		return nil, ErrInternal
+	}
+
+	res := toWarehouseDTO(warehouse)
+	return &res, nil
+}
+
+// UpdateWarehouse renames a warehouse and changes its capacity. Fields left out of the request
+// keep their values. Lowering the capacity below the occupied volume is allowed, but then the
+// warehouse accepts no more stock until enough of it is shipped.
+func (s *warehouseService) UpdateWarehouse(req *DTO.ReqUpdateWarehouse) (*DTO.Warehouse, error) {
+	s.Logger.Info("start service UpdateWarehouse")
+
+	if req.Name == "" && req.Capacity == nil {
+		return nil, ErrInvalidWarehouseName
+	}
+	if req.Capacity != nil && *req.Capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	warehouse, err := s.Repository.UpdateWarehouse(req.ID, req.Name, req.Capacity)
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	res := toWarehouseDTO(warehouse)
+	return &res, nil
+}
+
+// GetWarehouse returns a warehouse with its availability changes, latest first.
+func (s *warehouseService) GetWarehouse(req *DTO.ReqGetWarehouse) (*DTO.Warehouse, error) {
+	s.Logger.Info("start service GetWarehouse")
+
+	warehouse, err := s.Repository.GetWarehouse(req.ID)
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	history, err := s.Repository.AvailabilityHistory(req.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := toWarehouseDTO(warehouse)
+	res.History = make([]DTO.AvailabilityChange, 0, len(history))
+	for _, change := range history {
+		res.History = append(res.History, DTO.AvailabilityChange{
+			Available: change.Available,
+			Reason:    change.Reason,
+			UserID:    change.UserID,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+	return &res, nil
+}
+
+func (s *warehouseService) ListWarehouses() (*DTO.ResListWarehouses, error) {
+	s.Logger.Info("start service ListWarehouses")
+
+	warehouses, err := s.Repository.ListWarehouses()
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResListWarehouses{Warehouses: make([]DTO.Warehouse, 0, len(warehouses))}
+	for i := range warehouses {
+		res.Warehouses = append(res.Warehouses, toWarehouseDTO(&warehouses[i]))
+	}
+	return res, nil
+}
+
+// SetAvailability makes a warehouse available or unavailable. Every change is recorded with
+// the user who made it and the reason, which is required.
+func (s *warehouseService) SetAvailability(req *DTO.ReqSetWarehouseAvailability, user *AuthInfo) (*DTO.Warehouse, error) {
+	s.Logger.Info("start service SetAvailability")
+
+	if req.Reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	warehouse, err := s.Repository.SetAvailability(&model.AvailabilityChange{
+		WarehouseID: req.ID,
+		Available:   req.Available,
+		Reason:      req.Reason,
+		UserID:      user.ID,
+	})
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	res := toWarehouseDTO(warehouse)
+	return &res, nil
+}
+
+func toWarehouseDTO(warehouse *model.Warehouse) DTO.Warehouse {
+	return DTO.Warehouse{
+		ID:        warehouse.ID,
+		Name:      warehouse.Name,
+		Available: warehouse.Availability,
+		Capacity:  warehouse.Capacity,
+	}
+}
+
+// warehouseError maps a repository error for a change of a warehouse to the error reported to the client.
+func warehouseError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNonExistWarehouse
+	case errors.Is(err, repository.ErrWarehouseExists):
+		return ErrWarehouseExists
+	default:
+		return ErrInternal
+	}
+}
+
+// GetUtilization reports how much of its capacity every warehouse occupies, counting the volume
+// held for transfers in transit to it. Free space and the percentage are left out for
+// warehouses without a capacity.
+func (s *warehouseService) GetUtilization() (*DTO.ResGetUtilization, error) {
+	s.Logger.Info("start service GetUtilization")
+
+	utilization, err := s.Repository.Utilization()
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	res := &DTO.ResGetUtilization{Warehouses: make([]DTO.Utilization, 0, len(utilization))}
+	for _, u := range utilization {
+		item := DTO.Utilization{
+			WarehouseID: u.WarehouseID,
+			Name:        u.Name,
+			Capacity:    u.Capacity,
+			Occupied:    u.Occupied,
+			InTransit:   u.InTransit,
+		}
+		if u.Capacity > 0 {
+			used := u.Occupied + u.InTransit
+			free := u.Capacity - used
+			percent := float64(used) * 100 / float64(u.Capacity)
+			item.Free = &free
+			item.Percent = &percent
+		}
+		res.Warehouses = append(res.Warehouses, item)
+	}
+	return res, nil
+}
+
+// GetAvailability reports the free stock of each requested product in every available
+// warehouse, largest first, and its total across them. Products that no available warehouse
+// stocks are reported with a zero total.
+func (s *warehouseService) GetAvailability(req *DTO.ReqGetAvailability) (*DTO.ResGetAvailability, error) {
+	s.Logger.Info("start service GetAvailability")
+
+	if len(req.UniqueCodes) > maxPageSize {
+		return nil, ErrInvalidLimit
+	}
+
+	items, err := s.Repository.Availability(req.UniqueCodes)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	stock := make(map[string][]model.WarehouseProduct)
+	for _, item := range items {
+		stock[item.ProductCode] = append(stock[item.ProductCode], item)
+	}
+
+	res := &DTO.ResGetAvailability{Products: make([]DTO.ProductAvailability, 0, len(req.UniqueCodes))}
+	seen := make(map[string]bool)
+	for _, code := range req.UniqueCodes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		product := DTO.ProductAvailability{UniqueCode: code, Warehouses: make([]DTO.WarehouseStock, 0)}
+		for _, item := range stock[code] {
+			product.Total += item.LeftCount
+			product.Warehouses = append(product.Warehouses, DTO.WarehouseStock{
+				WarehouseID: item.WarehouseID,
+				LeftCount:   item.LeftCount,
+			})
+		}
+		res.Products = append(res.Products, product)
+	}
+	return res, nil
 }
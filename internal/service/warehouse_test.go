@@ -0,0 +1,498 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"example1/config"
+	"example1/internal/DTO"
+	"example1/internal/model"
+	"example1/internal/repository"
+	mock_repository "example1/internal/repository/mocks"
+	"example1/pkg/notifier"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestWarehouseService_UpdateWarehouse(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	capacity, negative := 500, -1
+	testTable := []struct {
+		name          string
+		req           DTO.ReqUpdateWarehouse
+		mockBehaviour mockBehaviour
+		expected      *DTO.Warehouse
+		expectedErr   error
+	}{
+		{
+			name: "Rename",
+			req:  DTO.ReqUpdateWarehouse{ID: 2, Name: "north"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().UpdateWarehouse(2, "north", nil).Return(
+					&model.Warehouse{ID: 2, Name: "north", Availability: true, Capacity: 100},
+					nil,
+				)
+			},
+			expected: &DTO.Warehouse{ID: 2, Name: "north", Available: true, Capacity: 100},
+		},
+		{
+			name: "Change capacity",
+			req:  DTO.ReqUpdateWarehouse{ID: 2, Capacity: &capacity},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().UpdateWarehouse(2, "", &capacity).Return(
+					&model.Warehouse{ID: 2, Name: "south", Availability: true, Capacity: 500},
+					nil,
+				)
+			},
+			expected: &DTO.Warehouse{ID: 2, Name: "south", Available: true, Capacity: 500},
+		},
+		{
+			name:          "Nothing to change",
+			req:           DTO.ReqUpdateWarehouse{ID: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidWarehouseName,
+		},
+		{
+			name:          "Negative capacity",
+			req:           DTO.ReqUpdateWarehouse{ID: 2, Capacity: &negative},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidCapacity,
+		},
+		{
+			name: "Name taken",
+			req:  DTO.ReqUpdateWarehouse{ID: 2, Name: "north"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().UpdateWarehouse(2, "north", nil).Return(nil, repository.ErrWarehouseExists)
+			},
+			expectedErr: ErrWarehouseExists,
+		},
+		{
+			name: "Non-existent warehouse",
+			req:  DTO.ReqUpdateWarehouse{ID: 12, Name: "north"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().UpdateWarehouse(12, "north", nil).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrNonExistWarehouse,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqUpdateWarehouse{ID: 2, Name: "north"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().UpdateWarehouse(2, "north", nil).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.UpdateWarehouse(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_GetUtilization(t *testing.T) {
+	free, percent := 900, 82.0
+	full, fullPercent := 0, 100.0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mock_repository.NewMockWarehouseRepository(ctrl)
+	r.EXPECT().Utilization().Return([]model.Utilization{
+		{WarehouseID: 1, Name: "south", Occupied: 1200, InTransit: 300},
+		{WarehouseID: 2, Name: "north", Capacity: 5000, Occupied: 3600, InTransit: 500},
+		{WarehouseID: 3, Name: "east", Capacity: 400, InTransit: 400},
+	}, nil)
+
+	s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+	res, err := s.GetUtilization()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &DTO.ResGetUtilization{Warehouses: []DTO.Utilization{
+		{WarehouseID: 1, Name: "south", Occupied: 1200, InTransit: 300},
+		{WarehouseID: 2, Name: "north", Capacity: 5000, Occupied: 3600, InTransit: 500, Free: &free, Percent: &percent},
+		{WarehouseID: 3, Name: "east", Capacity: 400, InTransit: 400, Free: &full, Percent: &fullPercent},
+	}}, res)
+}
+
+func TestWarehouseService_GetMovements(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	movements := []model.StockMovement{
+		{ID: 5, WarehouseID: 2, ProductCode: "olkiuj", Delta: 100, TotalDelta: 100, Reason: model.MovementReceive,
+			UserID: "lol", CreatedAt: createdAt},
+		{ID: 7, WarehouseID: 2, ProductCode: "olkiuj", Delta: -10, Reason: model.MovementReserve, ReservationID: 3,
+			UserID: "lol", CreatedAt: createdAt},
+	}
+	expected := []DTO.StockMovement{
+		{ID: 5, Delta: 100, TotalDelta: 100, Reason: model.MovementReceive, UserID: "lol", CreatedAt: createdAt},
+		{ID: 7, Delta: -10, Reason: model.MovementReserve, ReservationID: 3, UserID: "lol", CreatedAt: createdAt},
+	}
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqGetMovements
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResGetMovements
+		expectedErr   error
+	}{
+		{
+			name: "Full page",
+			req:  DTO.ReqGetMovements{WarehouseID: 2, UniqueCode: "olkiuj", From: from, To: to, Cursor: 4, Limit: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ListMovements(&model.MovementFilter{
+					WarehouseID: 2, ProductCode: "olkiuj", From: from, To: to, Cursor: 4, Limit: 2,
+				}).Return(movements, nil)
+			},
+			expected: &DTO.ResGetMovements{Movements: expected, NextCursor: 7},
+		},
+		{
+			name: "Last page",
+			req:  DTO.ReqGetMovements{WarehouseID: 2, UniqueCode: "olkiuj", Cursor: 4},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ListMovements(&model.MovementFilter{
+					WarehouseID: 2, ProductCode: "olkiuj", Cursor: 4, Limit: defaultPageSize,
+				}).Return(movements, nil)
+			},
+			expected: &DTO.ResGetMovements{Movements: expected},
+		},
+		{
+			name:          "Empty time range",
+			req:           DTO.ReqGetMovements{WarehouseID: 2, UniqueCode: "olkiuj", From: to, To: to},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidTimeRange,
+		},
+		{
+			name:          "Invalid limit",
+			req:           DTO.ReqGetMovements{WarehouseID: 2, UniqueCode: "olkiuj", Limit: -1},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidLimit,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqGetMovements{WarehouseID: 2, UniqueCode: "olkiuj"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().ListMovements(gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetMovements(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_Receive(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	expiresOn := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqReceiveProduct
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResReceiveProduct
+		expectedErr   error
+	}{
+		{
+			name: "OK",
+			req: DTO.ReqReceiveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{"olkiuj", "tghyuj"},
+				Counts:      []int{100, 20},
+				LotNumbers:  []string{"", "L-1"},
+				ExpiryDates: []string{"", "2027-03-01"},
+			},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().ReceiveProduct(&model.Receipt{WarehouseID: 2, ProductCode: "olkiuj", Count: 100, UserID: "lol"}).
+					Return(&model.WarehouseProduct{ProductCode: "olkiuj", TotalCount: 1100, LeftCount: 900}, nil, nil)
+				r.EXPECT().ReceiveProduct(&model.Receipt{
+					WarehouseID: 2, ProductCode: "tghyuj", Count: 20, LotNumber: "L-1", ExpiresOn: &expiresOn, UserID: "lol",
+				}).Return(
+					&model.WarehouseProduct{ProductCode: "tghyuj", TotalCount: 20, LeftCount: 20},
+					&model.Lot{
+						ID: 4, WarehouseID: 2, ProductCode: "tghyuj", LotNumber: "L-1", ExpiresOn: &expiresOn,
+						TotalCount: 20, LeftCount: 20,
+					},
+					nil,
+				)
+			},
+			expected: &DTO.ResReceiveProduct{
+				Successful: []DTO.Received{
+					{UniqueCode: "olkiuj", TotalCount: 1100, LeftCount: 900},
+					{UniqueCode: "tghyuj", TotalCount: 20, LeftCount: 20, Lot: &DTO.Lot{
+						ID: 4, WarehouseID: 2, UniqueCode: "tghyuj", LotNumber: "L-1", ExpiryDate: "2027-03-01",
+						TotalCount: 20, LeftCount: 20,
+					}},
+				},
+				Unsuccessful: []string{},
+				Errors:       []string{},
+			},
+		},
+		{
+			name: "Failed lines",
+			req: DTO.ReqReceiveProduct{
+				WarehouseID: 2,
+				UniqueCodes: []string{"olkiuj", "qwerty", "tghyuj", "lkjhgf"},
+				Counts:      []int{100, 5, 20, 0},
+				ExpiryDates: []string{"", "", "2027-03-01"},
+			},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(2).Return(true, nil)
+				r.EXPECT().ReceiveProduct(&model.Receipt{WarehouseID: 2, ProductCode: "olkiuj", Count: 100, UserID: "lol"}).
+					Return(nil, nil, repository.ErrCapacityExceeded)
+				r.EXPECT().ReceiveProduct(&model.Receipt{WarehouseID: 2, ProductCode: "qwerty", Count: 5, UserID: "lol"}).
+					Return(nil, nil, sql.ErrNoRows)
+				r.EXPECT().ReceiveProduct(&model.Receipt{WarehouseID: 2, ProductCode: "lkjhgf", UserID: "lol"}).
+					Return(nil, nil, repository.ErrInvalidCount)
+			},
+			expected: &DTO.ResReceiveProduct{
+				Successful:   []DTO.Received{},
+				Unsuccessful: []string{"olkiuj", "qwerty", "tghyuj", "lkjhgf"},
+				Errors: []string{
+					ErrCapacityExceeded.Error(), ErrInvalidUniqueCode.Error(), ErrLotNumberRequired.Error(),
+					ErrInvalidCount.Error(),
+				},
+			},
+		},
+		{
+			name: "Unavailable warehouse",
+			req:  DTO.ReqReceiveProduct{WarehouseID: 2, UniqueCodes: []string{"olkiuj"}, Counts: []int{100}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(2).Return(false, nil)
+			},
+			expectedErr: ErrWarehouseUnavailable,
+		},
+		{
+			name: "Non-existent warehouse",
+			req:  DTO.ReqReceiveProduct{WarehouseID: 12, UniqueCodes: []string{"olkiuj"}, Counts: []int{100}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().CheckAvailable(12).Return(false, sql.ErrNoRows)
+			},
+			expectedErr: ErrInvalidWarehouse,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.Receive(&test.req, &AuthInfo{ID: "lol", Role: warehouseWorker})
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_GetProducts(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	products := []model.ProductStock{
+		{ProductCode: "olkiuj", Name: "chair", Size: 3, TotalCount: 1100, LeftCount: 900, Reserved: 200},
+		{ProductCode: "tghyuj", Name: "table", Size: 5, TotalCount: 1200, LeftCount: 1200},
+	}
+	expected := []DTO.ProductStock{
+		{UniqueCode: "olkiuj", Name: "chair", Size: 3, TotalCount: 1100, LeftCount: 900, Reserved: 200},
+		{UniqueCode: "tghyuj", Name: "table", Size: 5, TotalCount: 1200, LeftCount: 1200},
+	}
+	// The cursor is base64 of the JSON array ["1200","tghyuj"].
+	cursor := "WyIxMjAwIiwidGdoeXVqIl0"
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqGetProducts
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResGetProducts
+		expectedErr   error
+	}{
+		{
+			name: "Filtered by code and name",
+			req:  DTO.ReqGetProducts{WarehouseID: 2, CodePrefix: "olk", Name: "chai", InStock: true},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(&model.ProductStockFilter{
+					WarehouseID: 2, CodePrefix: "olk", Name: "chai", InStock: true, Sort: model.SortByCode,
+					Limit: defaultPageSize,
+				}).Return(products[:1], nil)
+			},
+			expected: &DTO.ResGetProducts{Products: expected[:1]},
+		},
+		{
+			name: "Full page",
+			req:  DTO.ReqGetProducts{WarehouseID: 2, Sort: model.SortByTotalCount, Limit: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(&model.ProductStockFilter{WarehouseID: 2, Sort: model.SortByTotalCount, Limit: 2}).
+					Return(products, nil)
+			},
+			expected: &DTO.ResGetProducts{Products: expected, NextCursor: cursor},
+		},
+		{
+			name: "Page after the cursor",
+			req:  DTO.ReqGetProducts{WarehouseID: 2, Sort: model.SortByTotalCount, Desc: true, Cursor: cursor, Limit: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(&model.ProductStockFilter{
+					WarehouseID: 2, Sort: model.SortByTotalCount, Desc: true, Limit: 2,
+					After: &model.ProductStockKey{Value: "1200", ProductCode: "tghyuj"},
+				}).Return(products[:1], nil)
+			},
+			expected: &DTO.ResGetProducts{Products: expected[:1]},
+		},
+		{
+			name: "Empty page after the cursor",
+			req:  DTO.ReqGetProducts{WarehouseID: 2, Sort: model.SortByTotalCount, Cursor: cursor},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(gomock.Any()).Return([]model.ProductStock{}, nil)
+			},
+			expected: &DTO.ResGetProducts{Products: []DTO.ProductStock{}},
+		},
+		{
+			name: "No products",
+			req:  DTO.ReqGetProducts{WarehouseID: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(gomock.Any()).Return([]model.ProductStock{}, nil)
+			},
+			expectedErr: ErrNoProducts,
+		},
+		{
+			name:          "Invalid sort",
+			req:           DTO.ReqGetProducts{WarehouseID: 2, Sort: "size"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidSort,
+		},
+		{
+			name:          "Invalid cursor",
+			req:           DTO.ReqGetProducts{WarehouseID: 2, Cursor: "WyIxMjAwIl0"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidCursor,
+		},
+		{
+			name:          "Malformed cursor",
+			req:           DTO.ReqGetProducts{WarehouseID: 2, Cursor: "not a cursor"},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidCursor,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqGetProducts{WarehouseID: 2},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().AllProducts(gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetProducts(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestWarehouseService_GetAvailability(t *testing.T) {
+	type mockBehaviour func(r mock_repository.MockWarehouseRepository)
+
+	testTable := []struct {
+		name          string
+		req           DTO.ReqGetAvailability
+		mockBehaviour mockBehaviour
+		expected      *DTO.ResGetAvailability
+		expectedErr   error
+	}{
+		{
+			name: "Stock across warehouses",
+			req:  DTO.ReqGetAvailability{UniqueCodes: []string{"olkiuj", "tghyuj", "qwerty", "olkiuj"}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Availability([]string{"olkiuj", "tghyuj", "qwerty", "olkiuj"}).Return([]model.WarehouseProduct{
+					{WarehouseID: 3, ProductCode: "olkiuj", LeftCount: 900},
+					{WarehouseID: 2, ProductCode: "olkiuj", LeftCount: 100},
+					{WarehouseID: 2, ProductCode: "tghyuj", LeftCount: 1200},
+				}, nil)
+			},
+			expected: &DTO.ResGetAvailability{Products: []DTO.ProductAvailability{
+				{UniqueCode: "olkiuj", Total: 1000, Warehouses: []DTO.WarehouseStock{
+					{WarehouseID: 3, LeftCount: 900},
+					{WarehouseID: 2, LeftCount: 100},
+				}},
+				{UniqueCode: "tghyuj", Total: 1200, Warehouses: []DTO.WarehouseStock{{WarehouseID: 2, LeftCount: 1200}}},
+				{UniqueCode: "qwerty", Warehouses: []DTO.WarehouseStock{}},
+			}},
+		},
+		{
+			name: "No products",
+			req:  DTO.ReqGetAvailability{},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Availability(nil).Return([]model.WarehouseProduct{}, nil)
+			},
+			expected: &DTO.ResGetAvailability{Products: []DTO.ProductAvailability{}},
+		},
+		{
+			name:          "Too many products",
+			req:           DTO.ReqGetAvailability{UniqueCodes: make([]string, maxPageSize+1)},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {},
+			expectedErr:   ErrInvalidLimit,
+		},
+		{
+			name: "Repository error",
+			req:  DTO.ReqGetAvailability{UniqueCodes: []string{"olkiuj"}},
+			mockBehaviour: func(r mock_repository.MockWarehouseRepository) {
+				r.EXPECT().Availability([]string{"olkiuj"}).Return(nil, errors.New("connection refused"))
+			},
+			expectedErr: ErrInternal,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := mock_repository.NewMockWarehouseRepository(ctrl)
+			test.mockBehaviour(*r)
+
+			s := NewWarehouseService(r, config.Config{}, notifier.NewLogNotifier())
+			res, err := s.GetAvailability(&test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
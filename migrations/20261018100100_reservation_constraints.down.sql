@@ -0,0 +1,2 @@
+ALTER TABLE warehouse_product DROP CONSTRAINT warehouse_product_left_count_check;
+ALTER TABLE reservation DROP CONSTRAINT reservation_count_check;
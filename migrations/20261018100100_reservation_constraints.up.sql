@@ -0,0 +1,7 @@
+UPDATE warehouse_product SET left_count = 0 WHERE left_count < 0;
+
+ALTER TABLE warehouse_product
+    ADD CONSTRAINT warehouse_product_left_count_check CHECK (left_count >= 0);
+
+ALTER TABLE reservation
+    ADD CONSTRAINT reservation_count_check CHECK (count > 0);
@@ -0,0 +1,5 @@
+DROP INDEX reservation_expires_at_idx;
+
+ALTER TABLE reservation
+    DROP COLUMN expires_at,
+    DROP COLUMN created_at;
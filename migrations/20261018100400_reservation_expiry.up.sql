@@ -0,0 +1,5 @@
+ALTER TABLE reservation
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX reservation_expires_at_idx ON reservation (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE reservation_history CASCADE;
+
+ALTER TABLE reservation DROP COLUMN status;
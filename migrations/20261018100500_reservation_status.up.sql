@@ -0,0 +1,14 @@
+ALTER TABLE reservation
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'reserved';
+
+CREATE TABLE IF NOT EXISTS reservation_history
+(
+    id             serial primary key,
+    reservation_id INTEGER     NOT NULL REFERENCES reservation (id),
+    from_status    VARCHAR(20) NOT NULL,
+    to_status      VARCHAR(20) NOT NULL,
+    user_id        VARCHAR(40) REFERENCES users (id),
+    created_at     TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX reservation_history_reservation_id_idx ON reservation_history (reservation_id);
@@ -0,0 +1 @@
+ALTER TABLE reservation DROP COLUMN user_id;
@@ -0,0 +1,2 @@
+ALTER TABLE reservation
+    ADD COLUMN user_id VARCHAR(40) REFERENCES users (id);
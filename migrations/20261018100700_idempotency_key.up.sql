@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS idempotency_key
+(
+    user_id      VARCHAR(40)  NOT NULL REFERENCES users (id),
+    key          VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64)  NOT NULL,
+    status_code  INTEGER,
+    response     BYTEA,
+    created_at   TIMESTAMP    NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, key)
+);
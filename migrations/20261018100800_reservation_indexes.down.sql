@@ -0,0 +1,2 @@
+DROP INDEX reservation_user_id_idx;
+DROP INDEX reservation_warehouse_id_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX reservation_warehouse_id_idx ON reservation (warehouse_id, product_code);
+CREATE INDEX reservation_user_id_idx ON reservation (user_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS stock_movement
+(
+    id             serial primary key,
+    warehouse_id   INTEGER      NOT NULL REFERENCES warehouse (id),
+    product_code   VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    delta          INTEGER      NOT NULL,
+    total_delta    INTEGER      NOT NULL,
+    reason         VARCHAR(20)  NOT NULL,
+    reservation_id INTEGER REFERENCES reservation (id),
+    user_id        VARCHAR(40) REFERENCES users (id),
+    created_at     TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX stock_movement_product_idx ON stock_movement (warehouse_id, product_code, created_at);
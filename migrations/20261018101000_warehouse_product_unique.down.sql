@@ -0,0 +1 @@
+ALTER TABLE warehouse_product DROP CONSTRAINT warehouse_product_warehouse_id_product_code_key;
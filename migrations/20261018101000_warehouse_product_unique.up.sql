@@ -0,0 +1,2 @@
+ALTER TABLE warehouse_product
+    ADD CONSTRAINT warehouse_product_warehouse_id_product_code_key UNIQUE (warehouse_id, product_code);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS stock_transfer
+(
+    id                serial primary key,
+    from_warehouse_id INTEGER      NOT NULL REFERENCES warehouse (id),
+    to_warehouse_id   INTEGER      NOT NULL REFERENCES warehouse (id),
+    product_code      VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    count             INTEGER      NOT NULL CHECK (count > 0),
+    status            VARCHAR(20)  NOT NULL,
+    created_by        VARCHAR(40) REFERENCES users (id),
+    received_by       VARCHAR(40) REFERENCES users (id),
+    created_at        TIMESTAMP    NOT NULL DEFAULT now(),
+    received_at       TIMESTAMP
+);
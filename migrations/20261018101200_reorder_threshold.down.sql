@@ -0,0 +1 @@
+ALTER TABLE warehouse_product DROP COLUMN reorder_threshold;
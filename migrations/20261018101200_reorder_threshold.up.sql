@@ -0,0 +1,2 @@
+ALTER TABLE warehouse_product
+    ADD COLUMN reorder_threshold INTEGER NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0);
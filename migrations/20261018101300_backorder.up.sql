@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS backorder
+(
+    id             serial primary key,
+    warehouse_id   INTEGER      NOT NULL REFERENCES warehouse (id),
+    product_code   VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    count          INTEGER      NOT NULL CHECK (count > 0),
+    ttl            INTEGER      NOT NULL DEFAULT 0,
+    status         VARCHAR(20)  NOT NULL,
+    user_id        VARCHAR(40) REFERENCES users (id),
+    reservation_id INTEGER REFERENCES reservation (id),
+    created_at     TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX backorder_waiting_idx ON backorder (warehouse_id, product_code, id) WHERE status = 'waiting';
+CREATE INDEX backorder_user_id_idx ON backorder (user_id);
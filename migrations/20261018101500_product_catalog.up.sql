@@ -0,0 +1,2 @@
+ALTER TABLE product
+    ADD COLUMN deleted_at TIMESTAMP;
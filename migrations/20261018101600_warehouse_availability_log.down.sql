@@ -0,0 +1,6 @@
+DROP TABLE warehouse_availability_log CASCADE;
+
+ALTER TABLE warehouse
+    ALTER COLUMN available DROP NOT NULL,
+    ALTER COLUMN available DROP DEFAULT,
+    ALTER COLUMN name DROP NOT NULL;
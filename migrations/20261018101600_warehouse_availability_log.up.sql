@@ -0,0 +1,18 @@
+UPDATE warehouse SET available = false WHERE available IS NULL;
+
+ALTER TABLE warehouse
+    ALTER COLUMN available SET NOT NULL,
+    ALTER COLUMN available SET DEFAULT false,
+    ALTER COLUMN name SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS warehouse_availability_log
+(
+    id           serial primary key,
+    warehouse_id INTEGER      NOT NULL REFERENCES warehouse (id),
+    available    BOOLEAN      NOT NULL,
+    reason       VARCHAR(200) NOT NULL,
+    user_id      VARCHAR(40) REFERENCES users (id),
+    created_at   TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX warehouse_availability_log_warehouse_id_idx ON warehouse_availability_log (warehouse_id);
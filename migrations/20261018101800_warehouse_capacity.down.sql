@@ -0,0 +1,2 @@
+ALTER TABLE warehouse
+    DROP COLUMN capacity;
@@ -0,0 +1,2 @@
+ALTER TABLE warehouse
+    ADD COLUMN capacity INTEGER NOT NULL DEFAULT 0 CHECK (capacity >= 0);
@@ -0,0 +1,3 @@
+DROP TABLE stock_transfer_lot CASCADE;
+DROP TABLE reservation_lot CASCADE;
+DROP TABLE lot CASCADE;
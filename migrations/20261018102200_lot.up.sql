@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS lot
+(
+    id           serial primary key,
+    warehouse_id INTEGER      NOT NULL REFERENCES warehouse (id),
+    product_code VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    lot_number   VARCHAR(100) NOT NULL,
+    expires_on   DATE,
+    total_count  INTEGER      NOT NULL DEFAULT 0 CHECK (total_count >= 0),
+    left_count   INTEGER      NOT NULL DEFAULT 0 CHECK (left_count >= 0 AND left_count <= total_count),
+    created_at   TIMESTAMP    NOT NULL DEFAULT now(),
+    UNIQUE (warehouse_id, product_code, lot_number)
+);
+
+CREATE INDEX lot_expires_on_idx ON lot (expires_on) WHERE total_count > 0;
+
+CREATE TABLE IF NOT EXISTS reservation_lot
+(
+    reservation_id INTEGER NOT NULL REFERENCES reservation (id),
+    lot_id         INTEGER NOT NULL REFERENCES lot (id),
+    count          INTEGER NOT NULL CHECK (count > 0),
+    PRIMARY KEY (reservation_id, lot_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lot
+(
+    transfer_id INTEGER      NOT NULL REFERENCES stock_transfer (id),
+    lot_number  VARCHAR(100) NOT NULL,
+    expires_on  DATE,
+    count       INTEGER      NOT NULL CHECK (count > 0),
+    PRIMARY KEY (transfer_id, lot_number)
+);
@@ -0,0 +1,5 @@
+DROP TABLE serial_number_history CASCADE;
+DROP TABLE serial_number CASCADE;
+
+ALTER TABLE product
+    DROP COLUMN serialized;
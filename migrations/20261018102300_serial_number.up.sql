@@ -0,0 +1,34 @@
+ALTER TABLE product
+    ADD COLUMN serialized BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS serial_number
+(
+    id             serial primary key,
+    product_code   VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    serial_number  VARCHAR(100) NOT NULL,
+    warehouse_id   INTEGER      NOT NULL REFERENCES warehouse (id),
+    status         VARCHAR(20)  NOT NULL,
+    reservation_id INTEGER REFERENCES reservation (id),
+    transfer_id    INTEGER REFERENCES stock_transfer (id),
+    created_at     TIMESTAMP    NOT NULL DEFAULT now(),
+    UNIQUE (product_code, serial_number)
+);
+
+CREATE INDEX serial_number_available_idx ON serial_number (product_code, warehouse_id, id) WHERE status = 'available';
+CREATE INDEX serial_number_serial_number_idx ON serial_number (serial_number);
+CREATE INDEX serial_number_reservation_id_idx ON serial_number (reservation_id);
+CREATE INDEX serial_number_transfer_id_idx ON serial_number (transfer_id);
+
+CREATE TABLE IF NOT EXISTS serial_number_history
+(
+    id               serial primary key,
+    serial_number_id INTEGER     NOT NULL REFERENCES serial_number (id),
+    status           VARCHAR(20) NOT NULL,
+    warehouse_id     INTEGER     NOT NULL REFERENCES warehouse (id),
+    reservation_id   INTEGER REFERENCES reservation (id),
+    transfer_id      INTEGER REFERENCES stock_transfer (id),
+    user_id          VARCHAR(40) REFERENCES users (id),
+    created_at       TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX serial_number_history_serial_number_id_idx ON serial_number_history (serial_number_id, id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS stock_adjustment
+(
+    id             serial primary key,
+    warehouse_id   INTEGER      NOT NULL REFERENCES warehouse (id),
+    product_code   VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    delta          INTEGER      NOT NULL CHECK (delta <> 0),
+    reason         VARCHAR(40)  NOT NULL,
+    note           TEXT         NOT NULL DEFAULT '',
+    serial_numbers TEXT[],
+    status         VARCHAR(20)  NOT NULL,
+    created_by     VARCHAR(40) REFERENCES users (id),
+    reviewed_by    VARCHAR(40) REFERENCES users (id),
+    created_at     TIMESTAMP    NOT NULL DEFAULT now(),
+    reviewed_at    TIMESTAMP
+);
+
+CREATE INDEX stock_adjustment_warehouse_id_idx ON stock_adjustment (warehouse_id, id);
+CREATE INDEX stock_adjustment_pending_idx ON stock_adjustment (id) WHERE status = 'pending';
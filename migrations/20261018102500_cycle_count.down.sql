@@ -0,0 +1,2 @@
+DROP TABLE cycle_count_line CASCADE;
+DROP TABLE cycle_count CASCADE;
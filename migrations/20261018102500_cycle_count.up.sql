@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS cycle_count
+(
+    id            serial primary key,
+    warehouse_id  INTEGER     NOT NULL REFERENCES warehouse (id),
+    product_codes TEXT[],
+    status        VARCHAR(20) NOT NULL,
+    created_by    VARCHAR(40) REFERENCES users (id),
+    closed_by     VARCHAR(40) REFERENCES users (id),
+    created_at    TIMESTAMP   NOT NULL DEFAULT now(),
+    closed_at     TIMESTAMP
+);
+
+CREATE UNIQUE INDEX cycle_count_open_idx ON cycle_count (warehouse_id) WHERE status <> 'closed';
+
+CREATE TABLE IF NOT EXISTS cycle_count_line
+(
+    id             serial primary key,
+    cycle_count_id INTEGER      NOT NULL REFERENCES cycle_count (id),
+    product_code   VARCHAR(100) NOT NULL REFERENCES product (unique_code),
+    counted        INTEGER      NOT NULL CHECK (counted >= 0),
+    expected       INTEGER      NOT NULL,
+    status         VARCHAR(20)  NOT NULL,
+    adjusted       INTEGER      NOT NULL DEFAULT 0,
+    adjustment_id  INTEGER REFERENCES stock_adjustment (id),
+    counted_by     VARCHAR(40) REFERENCES users (id),
+    counted_at     TIMESTAMP    NOT NULL DEFAULT now(),
+    UNIQUE (cycle_count_id, product_code)
+);
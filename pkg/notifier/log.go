@@ -0,0 +1,18 @@
+package notifier
+
+import "example1/pkg/logger"
+
+type logNotifier struct {
+	Logger logger.Logger
+}
+
+// NewLogNotifier returns a notifier that writes events to the log.
+func NewLogNotifier() Notifier {
+	return &logNotifier{logger.Get()}
+}
+
+func (n *logNotifier) NotifyLowStock(event LowStockEvent) error {
+	n.Logger.Warnf("low stock: %d of %s left in warehouse %d, reorder threshold %d",
+		event.LeftCount, event.ProductCode, event.WarehouseID, event.Threshold)
+	return nil
+}
@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"example1/config"
+	"time"
+)
+
+// LowStockEvent is emitted when the free stock of a product in a warehouse drops below its
+// reorder threshold.
+type LowStockEvent struct {
+	WarehouseID int       `json:"warehouse_id"`
+	ProductCode string    `json:"product_code"`
+	LeftCount   int       `json:"left_count"`
+	Threshold   int       `json:"threshold"`
+	Time        time.Time `json:"time"`
+}
+
+type Notifier interface {
+	NotifyLowStock(event LowStockEvent) error
+}
+
+// New returns the notifier selected by the config, logging events by default.
+func New(c config.NotifierConfig) Notifier {
+	switch c.Type {
+	case "webhook":
+		return NewWebhookNotifier(c.WebhookURL, time.Duration(c.Timeout)*time.Second)
+	default:
+		return NewLogNotifier()
+	}
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"encoding/json"
+	"github.com/go-playground/assert/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_NotifyLowStock(t *testing.T) {
+	event := LowStockEvent{
+		WarehouseID: 2,
+		ProductCode: "olkiuj",
+		LeftCount:   3,
+		Threshold:   10,
+		Time:        time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+
+	testTable := []struct {
+		name        string
+		status      int
+		expectedErr bool
+	}{
+		{
+			name:   "OK",
+			status: http.StatusNoContent,
+		},
+		{
+			name:        "Webhook failed",
+			status:      http.StatusInternalServerError,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			var received LowStockEvent
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookNotifier(server.URL, time.Second).NotifyLowStock(event)
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, event, received)
+		})
+	}
+}